@@ -0,0 +1,105 @@
+//go:build js && wasm
+
+// json2yaml-wasm - expose the converter to JavaScript
+//
+// Build with GOOS=js GOARCH=wasm, and load with wasm_exec.js. The module
+// defines the global json2yaml object with the following functions.
+//
+//	json2yaml.convert(input: string | Uint8Array): string | Error
+//	json2yaml.createWriter(): {
+//	  write(chunk: string | Uint8Array): string | Error,
+//	  close(): string | Error,
+//	}
+//
+// The functions return an Error on invalid input, since the Go functions
+// cannot throw exceptions. The writer accepts the input in arbitrary chunks;
+// each call returns the output converted so far, and close returns the rest
+// of the output.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"syscall/js"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func main() {
+	js.Global().Set("json2yaml", js.ValueOf(map[string]any{
+		"convert":      js.FuncOf(convert),
+		"createWriter": js.FuncOf(createWriter),
+	}))
+	select {}
+}
+
+func convert(_ js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return newError("convert: expected 1 argument")
+	}
+	input, err := bytesOf(args[0])
+	if err != nil {
+		return newError(err.Error())
+	}
+	output, err := json2yaml.ConvertBytes(input)
+	if err != nil {
+		return newError(err.Error())
+	}
+	return string(output)
+}
+
+func createWriter(js.Value, []js.Value) any {
+	var b bytes.Buffer
+	s := json2yaml.NewStream(&b)
+	return js.ValueOf(map[string]any{
+		"write": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			if len(args) != 1 {
+				return newError("write: expected 1 argument")
+			}
+			chunk, err := bytesOf(args[0])
+			if err != nil {
+				return newError(err.Error())
+			}
+			if err := s.Push(chunk); err != nil {
+				return newError(err.Error())
+			}
+			if err := s.Flush(); err != nil {
+				return newError(err.Error())
+			}
+			return take(&b)
+		}),
+		"close": js.FuncOf(func(js.Value, []js.Value) any {
+			if err := s.Close(); err != nil {
+				return newError(err.Error())
+			}
+			return take(&b)
+		}),
+	})
+}
+
+// bytesOf returns the bytes of the string or the Uint8Array.
+func bytesOf(v js.Value) ([]byte, error) {
+	switch {
+	case v.Type() == js.TypeString:
+		return []byte(v.String()), nil
+	case v.InstanceOf(js.Global().Get("Uint8Array")):
+		bs := make([]byte, v.Length())
+		js.CopyBytesToGo(bs, v)
+		return bs, nil
+	default:
+		return nil, errInvalidInput
+	}
+}
+
+var errInvalidInput = errors.New("expected a string or an Uint8Array")
+
+func newError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
+
+// take returns the output written so far, and resets the buffer.
+func take(b *bytes.Buffer) string {
+	s := b.String()
+	b.Reset()
+	return s
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/itchyny/json2yaml"
+)
+
+// diffFile writes the difference between the YAML file next to the JSON file
+// and the YAML converted from the JSON file, and reports whether they differ.
+func diffFile(w io.Writer, name string, opts []json2yaml.Option) (bool, error) {
+	dst := yamlName(name)
+	if dst == name {
+		return false, fmt.Errorf("%s: cannot compare with the input file", name)
+	}
+	var buf bytes.Buffer
+	if err := convert(&buf, name, opts); err != nil {
+		return false, err
+	}
+	old, err := os.ReadFile(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if bytes.Equal(old, buf.Bytes()) {
+		return false, nil
+	}
+	_, err = io.WriteString(w, unifiedDiff(dst, name, string(old), buf.String()))
+	return true, err
+}
+
+const diffContext = 3
+
+// unifiedDiff returns the difference of the texts in the unified format.
+func unifiedDiff(xname, yname, x, y string) string {
+	xs, ys := splitLines(x), splitLines(y)
+	ops := diffLines(xs, ys)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", xname, yname)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// find the end of the hunk, merging the changes close to each other
+		start, end := i, i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		if start -= diffContext; start < 0 {
+			start = 0
+		}
+		if end += diffContext; end > len(ops) {
+			end = len(ops)
+		}
+		var xn, yn int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				xn++
+			}
+			if op.kind != '-' {
+				yn++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(ops[start].x, xn), hunkRange(ops[start].y, yn))
+		for _, op := range ops[start:end] {
+			line := op.line(xs, ys)
+			sb.WriteByte(op.kind)
+			sb.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is an operation of the edit script; x and y are the indices of the
+// lines before the operation.
+type diffOp struct {
+	kind byte // ' ', '-', or '+'
+	x, y int
+}
+
+func (op diffOp) line(xs, ys []string) string {
+	if op.kind == '+' {
+		return ys[op.y]
+	}
+	return xs[op.x]
+}
+
+// diffLines computes the shortest edit script from xs to ys with the linear
+// space variant of the Myers algorithm. The deletions are placed before the
+// insertions in each run of the changes.
+func diffLines(xs, ys []string) []diffOp {
+	d := &differ{xs: xs, ys: ys, ops: make([]diffOp, 0, len(xs)+len(ys))}
+	d.diff(0, len(xs), 0, len(ys))
+	ops := d.ops
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		x, y, dels, j := ops[i].x, ops[i].y, 0, i
+		for ; j < len(ops) && ops[j].kind != ' '; j++ {
+			if ops[j].kind == '-' {
+				dels++
+			}
+		}
+		for k := i; k < j; k++ {
+			if k-i < dels {
+				ops[k] = diffOp{'-', x + k - i, y}
+			} else {
+				ops[k] = diffOp{'+', x + dels, y + k - i - dels}
+			}
+		}
+		i = j
+	}
+	return ops
+}
+
+type differ struct {
+	xs, ys []string
+	ops    []diffOp
+	vf, vb []int
+}
+
+// diff appends the edit script from xs[x0:x1] to ys[y0:y1].
+func (d *differ) diff(x0, x1, y0, y1 int) {
+	for x0 < x1 && y0 < y1 && d.xs[x0] == d.ys[y0] {
+		d.ops = append(d.ops, diffOp{' ', x0, y0})
+		x0, y0 = x0+1, y0+1
+	}
+	var q int
+	for x0 < x1-q && y0 < y1-q && d.xs[x1-1-q] == d.ys[y1-1-q] {
+		q++
+	}
+	x1, y1 = x1-q, y1-q
+	switch {
+	case x0 == x1:
+		for y := y0; y < y1; y++ {
+			d.ops = append(d.ops, diffOp{'+', x0, y})
+		}
+	case y0 == y1:
+		for x := x0; x < x1; x++ {
+			d.ops = append(d.ops, diffOp{'-', x, y0})
+		}
+	default:
+		sx, sy, ex, ey := d.middleSnake(x0, x1, y0, y1)
+		d.diff(x0, sx, y0, sy)
+		for x, y := sx, sy; x < ex; x, y = x+1, y+1 {
+			d.ops = append(d.ops, diffOp{' ', x, y})
+		}
+		d.diff(ex, x1, ey, y1)
+	}
+	for i := 0; i < q; i++ {
+		d.ops = append(d.ops, diffOp{' ', x1 + i, y1 + i})
+	}
+}
+
+// middleSnake finds the snake in the middle of the shortest edit script from
+// xs[x0:x1] to ys[y0:y1], searching from both ends. It returns the start and
+// the end of the snake.
+func (d *differ) middleSnake(x0, x1, y0, y1 int) (int, int, int, int) {
+	n, m := x1-x0, y1-y0
+	max, delta := (n+m+1)/2, n-m
+	// vf[max+1+k] is the furthest x on the diagonal k (= x-y) from the start,
+	// and vb[max+1+c] is the furthest x on the diagonal c+delta from the end.
+	if size := 2*max + 3; len(d.vf) < size {
+		d.vf, d.vb = make([]int, size), make([]int, size)
+	}
+	vf, vb := d.vf, d.vb
+	vf[max+2], vb[max] = 0, n
+	for e := 0; e <= max; e++ {
+		for k := -e; k <= e; k += 2 {
+			var x int
+			if k == -e || k != e && vf[max+k] < vf[max+k+2] {
+				x = vf[max+k+2]
+			} else {
+				x = vf[max+k] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && d.xs[x0+x] == d.ys[y0+y] {
+				x, y = x+1, y+1
+			}
+			vf[max+1+k] = x
+			if c := k - delta; delta%2 != 0 && -e < c && c < e && vb[max+1+c] <= x {
+				return x0 + sx, y0 + sy, x0 + x, y0 + y
+			}
+		}
+		for c := -e; c <= e; c += 2 {
+			var x int
+			if c == e || c != -e && vb[max+c] < vb[max+c+2]-1 {
+				x = vb[max+c]
+			} else {
+				x = vb[max+c+2] - 1
+			}
+			k := c + delta
+			y := x - k
+			ex, ey := x, y
+			for x > 0 && y > 0 && d.xs[x0+x-1] == d.ys[y0+y-1] {
+				x, y = x-1, y-1
+			}
+			vb[max+1+c] = x
+			if delta%2 == 0 && -e <= k && k <= e && vf[max+1+k] >= x {
+				return x0 + x, y0 + y, x0 + ex, y0 + ey
+			}
+		}
+	}
+	panic("unreachable")
+}
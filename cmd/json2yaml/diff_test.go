@@ -0,0 +1,139 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		name string
+		x, y string
+		want string
+	}{
+		{
+			name: "same",
+			x:    "a\nb\n",
+			y:    "a\nb\n",
+			want: "--- x\n+++ y\n",
+		},
+		{
+			name: "new file",
+			x:    "",
+			y:    "a\nb\n",
+			want: "--- x\n+++ y\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "change",
+			x:    "a\nb\nc\n",
+			y:    "a\nx\nc\n",
+			want: "--- x\n+++ y\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "deletions before insertions",
+			x:    "a\nb\nc\nd\n",
+			y:    "x\nb\ny\nd\n",
+			want: "--- x\n+++ y\n@@ -1,4 +1,4 @@\n-a\n+x\n b\n-c\n+y\n d\n",
+		},
+		{
+			name: "separate hunks",
+			x:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			y:    "0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n13\n",
+			want: "--- x\n+++ y\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+13\n",
+		},
+		{
+			name: "merged hunks",
+			x:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			y:    "0\n2\n3\n4\n5\n6\n7\n9\n",
+			want: "--- x\n+++ y\n@@ -1,8 +1,8 @@\n-1\n+0\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+9\n",
+		},
+		{
+			name: "insertion in the middle",
+			x:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			y:    "1\n2\n3\n4\nx\n5\n6\n7\n8\n",
+			want: "--- x\n+++ y\n@@ -2,6 +2,7 @@\n 2\n 3\n 4\n+x\n 5\n 6\n 7\n",
+		},
+		{
+			name: "deletion of a line",
+			x:    "a\nb\n",
+			y:    "a\n",
+			want: "--- x\n+++ y\n@@ -1,2 +1 @@\n a\n-b\n",
+		},
+		{
+			name: "no newline at end of file",
+			x:    "a\nb",
+			y:    "a\nb\n",
+			want: "--- x\n+++ y\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := unifiedDiff("x", "y", tc.x, tc.y); got != tc.want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		xs := make([]string, r.Intn(20))
+		for i := range xs {
+			xs[i] = string(rune('a' + r.Intn(4)))
+		}
+		return xs
+	}
+	for i := 0; i < 1000; i++ {
+		xs, ys := lines(), lines()
+		ops := diffLines(xs, ys)
+		var got []string
+		var edits, x, y int
+		for _, op := range ops {
+			if op.x != x || op.y != y {
+				t.Fatalf("invalid operation %+v at (%d, %d): %q -> %q", op, x, y, xs, ys)
+			}
+			switch op.kind {
+			case ' ':
+				if xs[x] != ys[y] {
+					t.Fatalf("invalid operation %+v: %q -> %q", op, xs, ys)
+				}
+				got = append(got, xs[x])
+				x, y = x+1, y+1
+			case '-':
+				edits++
+				x++
+			case '+':
+				got = append(got, ys[y])
+				edits++
+				y++
+			}
+		}
+		if x != len(xs) || strings.Join(got, "") != strings.Join(ys, "") {
+			t.Fatalf("invalid edit script %+v: %q -> %q", ops, xs, ys)
+		}
+		if want := len(xs) + len(ys) - 2*lcsLength(xs, ys); edits != want {
+			t.Fatalf("should edit %d lines but got %d: %q -> %q", want, edits, xs, ys)
+		}
+	}
+}
+
+func lcsLength(xs, ys []string) int {
+	lcs := make([][]int, len(xs)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(ys)+1)
+	}
+	for i := len(xs) - 1; i >= 0; i-- {
+		for j := len(ys) - 1; j >= 0; j-- {
+			if xs[i] == ys[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	return lcs[0][0]
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readFileList reads the list of the input files from the file, or stdin if
+// the name is "-". The names are separated by NUL if the list contains NUL
+// (e.g. the output of find -print0), or by newlines otherwise.
+func readFileList(name string) ([]string, error) {
+	var bs []byte
+	var err error
+	if name == "-" {
+		bs, err = io.ReadAll(os.Stdin)
+	} else {
+		bs, err = os.ReadFile(filepath.Clean(name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	sep := "\n"
+	if bytes.IndexByte(bs, 0) >= 0 {
+		sep = "\x00"
+	}
+	var names []string
+	for _, s := range strings.Split(string(bs), sep) {
+		if sep == "\n" {
+			s = strings.TrimSuffix(s, "\r")
+		}
+		if s != "" {
+			names = append(names, s)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/itchyny/json2yaml"
+)
+
+// followFile converts the file as it grows like tail -f, writing each document
+// as soon as the value is complete. It returns only on errors.
+func followFile(w io.Writer, name string, interval time.Duration, opts []json2yaml.Option) error {
+	return withInput(name, func(r io.Reader) error {
+		return json2yaml.Convert(w, &followReader{r: r, interval: interval},
+			append(opts[:len(opts):len(opts)], json2yaml.WithDecompress(),
+				json2yaml.WithErrorPosition(), json2yaml.WithDocumentFlush())...)
+	})
+}
+
+// followReader waits for the file to grow at the end of the file, polling at
+// the interval.
+type followReader struct {
+	r        io.Reader
+	interval time.Duration
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.r.Read(p)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+		if f, ok := r.r.(*os.File); ok {
+			if truncated(f) {
+				return 0, errors.New("file truncated")
+			}
+		}
+		time.Sleep(r.interval)
+	}
+}
+
+func truncated(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	return err == nil && fi.Size() < offset
+}
@@ -2,11 +2,18 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/itchyny/json2yaml"
 )
@@ -22,8 +29,11 @@ func main() {
 }
 
 const (
-	exitCodeOK = iota
-	exitCodeErr
+	exitCodeOK       = iota
+	exitCodeErr      // I/O error
+	exitCodeUsageErr // invalid flags
+	exitCodeParseErr // invalid input
+	exitCodeDiff     // differences found by -diff
 )
 
 func run(args []string) (exitCode int) {
@@ -36,61 +46,333 @@ func run(args []string) (exitCode int) {
 Version: %s (rev: %s/%s)
 
 Synopsis:
-  %% %[1]s file ...
+  %% %[1]s [options] [-o file] file|url ...
+  %% %[1]s [options] [-write] -files list|-
+  %% %[1]s [options] -write [-remove] [-r] [-jobs n] file ...
+  %% %[1]s [options] -diff [-r] file ...
+  %% %[1]s -check [-r] file ...
+  %% %[1]s [options] -merge [-key-template template] [-o file] file ...
+  %% %[1]s [options] -serve address
+  %% %[1]s [options] -watch [-o file | -write] file ...
+  %% %[1]s [options] -follow [-interval duration] [-o file] file
 
 Options:
 `, name, version, revision, runtime.Version())
 		fs.PrintDefaults()
+		fmt.Print(`
+Exit status:
+  0 on success, 1 on I/O errors, 2 on invalid flags, 3 on invalid input,
+  4 if -diff finds differences
+`)
 	}
 	var showVersion bool
 	fs.BoolVar(&showVersion, "version", false, "print version")
+	var output string
+	fs.StringVar(&output, "o", "", "write output to `file`")
+	var write, remove, diff bool
+	fs.BoolVar(&write, "write", false, "write each file.json to file.yaml")
+	fs.BoolVar(&remove, "remove", false, "remove the input files written by -write")
+	fs.BoolVar(&diff, "diff", false, "print the difference from each file.yaml instead of writing")
+	var check bool
+	fs.BoolVar(&check, "check", false, "check that the inputs are valid JSON without converting")
+	var addr string
+	fs.StringVar(&addr, "serve", "", "run the HTTP server at the `address` (e.g. :8080) converting the request bodies")
+	var colorMode string
+	fs.StringVar(&colorMode, "color", "auto", "`when` to colorize the output (auto, always, or never)")
+	var sourceComments bool
+	fs.BoolVar(&sourceComments, "source-comments", false, "write the input file name as a comment at the start of each document")
+	var merge bool
+	fs.BoolVar(&merge, "merge", false, "merge the files into a mapping with the keys from the file names")
+	var keyTemplate string
+	fs.StringVar(&keyTemplate, "key-template", "{{.Name}}",
+		"`template` of the keys of -merge (.Path, .Dir, .Base, and .Name of the file)")
+	var fileList string
+	fs.StringVar(&fileList, "files", "", "read the input file names from the `file` (- for stdin), separated by newlines or NUL")
+	var recursive bool
+	fs.BoolVar(&recursive, "r", false, "convert the .json files in the directories recursively")
+	var jobs int
+	fs.IntVar(&jobs, "jobs", runtime.NumCPU(), "number of files converted in parallel with -write")
+	fs.DurationVar(&urlTimeout, "timeout", 30*time.Second, "`timeout` of fetching the URL inputs")
+	fs.Int64Var(&urlMaxSize, "max-size", 0, "maximum size of the URL inputs in `bytes` (0 for no limit)")
+	var watch bool
+	fs.BoolVar(&watch, "watch", false, "convert again when the input files are modified")
+	var follow bool
+	fs.BoolVar(&follow, "follow", false, "keep converting the input file as it grows (like tail -f)")
+	var interval time.Duration
+	fs.DurationVar(&interval, "interval", time.Second, "polling `interval` of -watch and -follow")
+	var o options
+	optionFlags(fs, &o)
 	if err := fs.Parse(args); err != nil {
 		if err == flag.ErrHelp {
 			return exitCodeOK
 		}
-		return exitCodeErr
+		return exitCodeUsageErr
 	}
 	if showVersion {
 		fmt.Printf("%s %s (rev: %s/%s)\n", name, version, revision, runtime.Version())
 		return exitCodeOK
 	}
-	if args = fs.Args(); len(args) == 0 {
-		if err := convert("-"); err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
-			exitCode = exitCodeErr
+	if write && output != "" {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -o with -write\n", name)
+		return exitCodeUsageErr
+	} else if check && (write || diff || output != "") {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -check with -o, -write, or -diff\n", name)
+		return exitCodeUsageErr
+	} else if diff && (write || output != "") {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -diff with -o or -write\n", name)
+		return exitCodeUsageErr
+	} else if merge && (write || diff || check) {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -merge with -write, -diff, or -check\n", name)
+		return exitCodeUsageErr
+	} else if merge && o.schemaComments {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -merge with -schema\n", name)
+		return exitCodeUsageErr
+	} else if addr != "" && (write || diff || check || merge || watch || output != "" || fs.NArg() > 0 || fileList != "") {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -serve with the input files or the other modes\n", name)
+		return exitCodeUsageErr
+	} else if follow && (write || diff || check || merge || watch || addr != "" ||
+		fs.NArg() != 1 || fs.Arg(0) == "-" || isURL(fs.Arg(0)) || fileList != "") {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -follow except with one input file\n", name)
+		return exitCodeUsageErr
+	} else if remove && !write {
+		fmt.Fprintf(os.Stderr, "%s: cannot use -remove without -write\n", name)
+		return exitCodeUsageErr
+	}
+	args, opts := fs.Args(), o.all()
+	if fileList != "" {
+		names, err := readFileList(fileList)
+		if err != nil {
+			return failure(exitCodeOK, err)
+		}
+		args = append(args, names...)
+	}
+	if len(args) == 0 && fileList == "" {
+		if recursive {
+			args = []string{"."}
+		} else if write || diff || merge || watch {
+			fmt.Fprintf(os.Stderr, "%s: no input files\n", name)
+			return exitCodeUsageErr
+		}
+	}
+	if addr != "" {
+		if err := serve(addr, opts); err != nil {
+			return failure(exitCodeOK, err)
+		}
+		return exitCodeOK
+	}
+	var color bool
+	switch colorMode {
+	case "auto":
+		color = output == "" && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	case "always":
+		color = true
+	case "never":
+	default:
+		fmt.Fprintf(os.Stderr, "%s: invalid value for -color: %s\n", name, colorMode)
+		return exitCodeUsageErr
+	}
+	separator := "---"
+	if color {
+		separator = "\x1b[90m---\x1b[0m"
+	}
+	tmpl, err := template.New("key").Parse(keyTemplate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return exitCodeUsageErr
+	}
+	convertAll := func() (exitCode int) {
+		args := args
+		if recursive {
+			var err error
+			if args, err = findFiles(args); err != nil {
+				return failure(exitCodeOK, err)
+			}
+		}
+		if write {
+			return writeFiles(args, remove, jobs, opts)
+		}
+		if check {
+			if len(args) == 0 {
+				args = []string{"-"}
+			}
+			for _, arg := range args {
+				if err := validate(arg); err != nil {
+					exitCode = failure(exitCode, err)
+				}
+			}
+			return
+		}
+		if diff {
+			for _, arg := range args {
+				if differs, err := diffFile(os.Stdout, arg, opts); err != nil {
+					exitCode = failure(exitCode, err)
+				} else if differs {
+					exitCode = combineExitCodes(exitCode, exitCodeDiff)
+				}
+			}
+			return
+		}
+		w := io.Writer(os.Stdout)
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return failure(exitCodeOK, err)
+			}
+			defer func() {
+				if err := f.Close(); err != nil {
+					exitCode = failure(exitCode, err)
+				}
+			}()
+			w = f
+		}
+		opts, outputOpts := opts, o.output
+		if color {
+			opts = append(opts[:len(opts):len(opts)], json2yaml.WithColor())
+			outputOpts = append(outputOpts[:len(outputOpts):len(outputOpts)], json2yaml.WithColor())
+		}
+		if merge {
+			return mergeFiles(w, args, tmpl, o.input, outputOpts, o.tags)
+		}
+		if follow {
+			return failure(exitCodeOK, followFile(w, args[0], interval, opts))
+		}
+		if len(args) == 0 && !recursive && fileList == "" {
+			args = []string{"-"}
 		}
-	} else {
 		for i, arg := range args {
 			if i > 0 {
-				fmt.Fprintln(os.Stdout, "---")
+				fmt.Fprintln(w, separator)
 			}
-			if err := convert(arg); err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
-				exitCode = exitCodeErr
+			opts := opts
+			if sourceComments {
+				source := arg
+				if source == "-" {
+					source = "<stdin>"
+				}
+				opts = append(opts[:len(opts):len(opts)], json2yaml.WithHeader("source: "+source))
 			}
+			if err := convert(w, arg, opts); err != nil {
+				exitCode = failure(exitCode, err)
+			}
+		}
+		return
+	}
+	if watch {
+		if output == "" && !write && !diff && !check {
+			// Separate the outputs of the runs to stdout as documents.
+			convertAll = separateRuns(os.Stdout, separator, convertAll)
 		}
+		watchFiles(args, interval, convertAll)
 	}
-	return
+	return convertAll()
 }
 
-func convert(name string) (err error) {
+// failure reports the error, and returns the exit code combined with the
+// previous one.
+func failure(exitCode int, err error) int {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+	var serr *json.SyntaxError
+	var verr *json2yaml.ValidationError
+	if errors.As(err, &serr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &verr) || errors.Is(err, json2yaml.ErrInvalidNumber) ||
+		errors.Is(err, json2yaml.ErrEmptyInput) {
+		return combineExitCodes(exitCode, exitCodeParseErr)
+	}
+	return combineExitCodes(exitCode, exitCodeErr)
+}
+
+// combineExitCodes returns the exit code of the more serious failure; an I/O
+// error takes precedence over a parse error, and a parse error over the
+// differences.
+func combineExitCodes(x, y int) int {
+	if x == exitCodeOK || y != exitCodeOK && y < x {
+		return y
+	}
+	return x
+}
+
+func convert(w io.Writer, name string, opts []json2yaml.Option) error {
+	return withInput(name, func(r io.Reader) error {
+		return json2yaml.Convert(w, r, append(opts[:len(opts):len(opts)],
+			json2yaml.WithDecompress(), json2yaml.WithErrorPosition())...)
+	})
+}
+
+func validate(name string) error {
+	return withInput(name, func(r io.Reader) error {
+		return json2yaml.Validate(r, json2yaml.WithDecompress())
+	})
+}
+
+// withInput calls the function with the input file, and prefixes the error
+// with the file name and the position.
+func withInput(name string, f func(io.Reader) error) (err error) {
+	var r io.ReadCloser = os.Stdin
 	if name == "-" {
-		if err := json2yaml.Convert(os.Stdout, os.Stdin); err != nil {
-			return fmt.Errorf("<stdin>: %w", err)
+		name = "<stdin>"
+	} else {
+		if isURL(name) {
+			r, err = openURL(name)
+		} else {
+			r, err = os.Open(filepath.Clean(name))
 		}
-		return nil
-	}
-	f, err := os.Open(filepath.Clean(name))
-	if err != nil {
-		return err
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := r.Close(); err == nil {
+				err = cerr
+			}
+		}()
 	}
-	defer func() {
-		if cerr := f.Close(); err == nil {
-			err = cerr
+	if err := f(r); err != nil {
+		var perr *json2yaml.ParseError
+		if errors.As(err, &perr) && perr.Pos.Line > 0 {
+			return fmt.Errorf("%s:%d:%d: %w", name, perr.Pos.Line, perr.Pos.Column, perr.Err)
 		}
-	}()
-	if err := json2yaml.Convert(os.Stdout, f); err != nil {
 		return fmt.Errorf("%s: %w", name, err)
 	}
 	return nil
 }
+
+// findFiles replaces the directories with the .json (or .json.gz) files in
+// them.
+func findFiles(args []string) ([]string, error) {
+	var names []string
+	for _, arg := range args {
+		if fi, err := os.Stat(arg); err != nil || !fi.IsDir() {
+			names = append(names, arg)
+			continue
+		}
+		if err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isJSONFile(path) {
+				names = append(names, path)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+func isJSONFile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")
+}
+
+// yamlName returns the name of the YAML file for the JSON file.
+func yamlName(name string) string {
+	if strings.EqualFold(filepath.Ext(name), ".gz") {
+		name = name[:len(name)-3]
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".yaml"
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,759 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testCase struct {
+	name     string
+	args     []string
+	files    map[string]string
+	input    string
+	want     string
+	wantErr  string
+	wantFile map[string]string
+	removed  []string
+	usage    bool // the usage is written on the invalid flag value
+	exitCode int
+}
+
+func TestRun(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:  "file",
+			args:  []string{"a.json"},
+			files: map[string]string{"a.json": `{"foo":[1,2]}`},
+			want:  "foo:\n  - 1\n  - 2\n",
+		},
+		{
+			name:  "stdin",
+			input: `{"foo":"bar"} [1]`,
+			want:  "foo: bar\n---\n- 1\n",
+		},
+		{
+			name:     "invalid flag",
+			args:     []string{"-color", "x"},
+			wantErr:  "json2yaml: invalid value for -color: x\n",
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:     "file not found",
+			args:     []string{"x.json"},
+			wantErr:  "json2yaml: open x.json: no such file or directory\n",
+			exitCode: exitCodeErr,
+		},
+		{
+			name:     "invalid input",
+			args:     []string{"a.json"},
+			files:    map[string]string{"a.json": `{"foo":}`},
+			want:     "foo:\n",
+			wantErr:  "json2yaml: a.json:1:8: invalid character '}' looking for beginning of value\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:  "multiple files",
+			args:  []string{"a.json", "-", "b.json"},
+			files: map[string]string{"a.json": `{"foo":1}`, "b.json": `[2]`},
+			input: `"bar"`,
+			want:  "foo: 1\n---\nbar\n---\n- 2\n",
+		},
+		{
+			name:     "output file",
+			args:     []string{"-o", "out.yaml", "a.json"},
+			files:    map[string]string{"a.json": `{"foo":1}`},
+			wantFile: map[string]string{"out.yaml": "foo: 1\n"},
+		},
+		{
+			name:     "output file error",
+			args:     []string{"-o", "x/out.yaml", "a.json"},
+			files:    map[string]string{"a.json": `{"foo":1}`},
+			wantErr:  "json2yaml: open x/out.yaml: no such file or directory\n",
+			exitCode: exitCodeErr,
+		},
+		{
+			name:  "source comments",
+			args:  []string{"-source-comments", "a.json", "-"},
+			files: map[string]string{"a.json": `{"foo":1}`},
+			input: `1 2`,
+			want:  "# source: a.json\nfoo: 1\n---\n# source: <stdin>\n1\n---\n# source: <stdin>\n2\n",
+		},
+		{
+			name:  "color always",
+			args:  []string{"-color", "always", "a.json", "a.json"},
+			files: map[string]string{"a.json": `{"foo":1}`},
+			want:  "\x1b[34;1mfoo\x1b[0m: \x1b[36m1\x1b[0m\n\x1b[90m---\x1b[0m\n\x1b[34;1mfoo\x1b[0m: \x1b[36m1\x1b[0m\n",
+		},
+		{
+			name:  "color never",
+			args:  []string{"-color", "never", "a.json"},
+			files: map[string]string{"a.json": `{"foo":1}`},
+			want:  "foo: 1\n",
+		},
+		{
+			name:  "files",
+			args:  []string{"-files", "list.txt"},
+			files: map[string]string{"list.txt": "a.json\r\n\nb.json\n", "a.json": `1`, "b.json": `2`},
+			want:  "1\n---\n2\n",
+		},
+		{
+			name:  "files from stdin separated by nul",
+			args:  []string{"-files", "-", "a.json"},
+			files: map[string]string{"a.json": `1`, "b c.json": `2`},
+			input: "b c.json\x00a.json\x00",
+			want:  "1\n---\n2\n---\n1\n",
+		},
+		{
+			name:     "files not found",
+			args:     []string{"-files", "list.txt"},
+			wantErr:  "json2yaml: open list.txt: no such file or directory\n",
+			exitCode: exitCodeErr,
+		},
+		{
+			name:  "recursive",
+			args:  []string{"-r"},
+			files: map[string]string{"a.json": `1`, "x/b.JSON": `2`, "x/c.txt": `3`},
+			want:  "1\n---\n2\n",
+		},
+		{
+			name:  "path and split",
+			args:  []string{"-path", "items", "-split"},
+			input: `{"items":[{"a":1},{"b":2}]}`,
+			want:  "a: 1\n---\nb: 2\n",
+		},
+		{
+			name:  "wrap",
+			args:  []string{"-wrap"},
+			input: "{\"a\":1}\n{\"b\":2}\n",
+			want:  "- a: 1\n- b: 2\n",
+		},
+		{
+			name:  "kubernetes list",
+			args:  []string{"-k8s-list"},
+			input: `{"apiVersion":"v1","kind":"List","items":[{"kind":"A"},{"kind":"B"}]}`,
+			want:  "kind: A\n---\nkind: B\n",
+		},
+		{
+			name:  "redact",
+			args:  []string{"-redact", "*password*", "-redact", "token"},
+			input: `{"user":"foo","db_password":"bar","token":[1]}`,
+			want:  "user: foo\ndb_password: \"***\"\ntoken: \"***\"\n",
+		},
+		{
+			name:  "expand json",
+			args:  []string{"-expand-json"},
+			input: `{"payload":"{\"a\":[1]}"}`,
+			want:  "payload:\n  a:\n    - 1\n",
+		},
+		{
+			name:  "tag",
+			args:  []string{"-tag", "a=!!str", "-tag-directive", "!e!=tag:example.com,2000:", "-tag", "b=!e!x"},
+			input: `{"a":1,"b":2}`,
+			want:  "%TAG !e! tag:example.com,2000:\n---\na: !!str 1\nb: !e!x 2\n",
+		},
+		{
+			name:     "invalid tag",
+			args:     []string{"-tag", "a"},
+			wantErr:  "invalid value \"a\" for flag -tag: expected pattern=tag\n",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:     "invalid tag directive",
+			args:     []string{"-tag-directive", "a"},
+			wantErr:  "invalid value \"a\" for flag -tag-directive: expected handle=prefix\n",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:     "strict numbers",
+			args:     []string{"-strict-numbers"},
+			input:    `[1, 01]`,
+			want:     "- 1\n- \n",
+			wantErr:  "json2yaml: <stdin>:1:5: invalid number literal: 01\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:  "empty null",
+			args:  []string{"-empty", "null"},
+			input: " ",
+			want:  "null\n",
+		},
+		{
+			name:     "empty error",
+			args:     []string{"-empty", "error"},
+			wantErr:  "json2yaml: <stdin>: empty input\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:     "invalid empty",
+			args:     []string{"-empty", "x"},
+			wantErr:  "invalid value \"x\" for flag -empty: expected ignore, null, or error\n",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:  "line width",
+			args:  []string{"-line-width", "10"},
+			input: `["foo bar baz qux "]`,
+			want:  "- \"foo bar\n  baz\n  qux \"\n",
+		},
+		{
+			name:     "invalid line width",
+			args:     []string{"-line-width", "x"},
+			wantErr:  "invalid value \"x\" for flag -line-width: ",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:  "preset",
+			args:  []string{"-preset", "helm"},
+			input: `{"a":{"b":1},"c":2}`,
+			want:  "a:\n  b: 1\n\nc: 2\n",
+		},
+		{
+			name:     "invalid preset",
+			args:     []string{"-preset", "x"},
+			wantErr:  "invalid value \"x\" for flag -preset: expected helm\n",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:  "truncate",
+			args:  []string{"-truncate", "3"},
+			input: `["abcdef"]`,
+			want:  "- abc # truncated, 6B\n",
+		},
+		{
+			name:     "invalid truncate",
+			args:     []string{"-truncate", "x"},
+			wantErr:  "invalid value \"x\" for flag -truncate: ",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:  "header",
+			args:  []string{"-header", "generated"},
+			input: `1 2`,
+			want:  "# generated\n1\n---\n# generated\n2\n",
+		},
+		{
+			name:  "schema",
+			args:  []string{"-schema", "schema.json"},
+			files: map[string]string{"schema.json": `{"properties":{"a":{"description":"the value"}}}`},
+			input: `{"a":1}`,
+			want:  "# the value\na: 1\n",
+		},
+		{
+			name:     "schema not found",
+			args:     []string{"-schema", "schema.json"},
+			wantErr:  "invalid value \"schema.json\" for flag -schema: open schema.json: no such file or directory\n",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:     "validate",
+			args:     []string{"-validate", "schema.json"},
+			files:    map[string]string{"schema.json": `{"properties":{"a":{"type":"string"}}}`},
+			input:    `{"a":1}`,
+			want:     "a:\n",
+			wantErr:  "json2yaml: <stdin>: schema validation failed at \"/a\": expected string but got integer\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:     "invalid schema",
+			args:     []string{"-validate", "schema.json"},
+			files:    map[string]string{"schema.json": `[`},
+			wantErr:  "invalid value \"schema.json\" for flag -validate: json2yaml: invalid schema: ",
+			usage:    true,
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:  "flush",
+			args:  []string{"-flush"},
+			input: `1 2`,
+			want:  "1\n---\n2\n",
+		},
+		{
+			name:     "no input files",
+			args:     []string{"-merge"},
+			wantErr:  "json2yaml: no input files\n",
+			exitCode: exitCodeUsageErr,
+		},
+		{
+			name:     "serve error",
+			args:     []string{"-serve", "localhost:x"},
+			wantErr:  "json2yaml: listen tcp: ",
+			exitCode: exitCodeErr,
+		},
+		{
+			name:     "invalid key template",
+			args:     []string{"-key-template", "{{"},
+			wantErr:  "json2yaml: template: key:1: unclosed action\n",
+			exitCode: exitCodeUsageErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRun(t, tc)
+		})
+	}
+}
+
+func TestRunMerge(t *testing.T) {
+	files := map[string]string{
+		"a.json":      `{"x":{"k":1},"z":"foo"}`,
+		"b.json":      `{"x":{"k":2}}`,
+		"schema.json": `{"type":"object","required":["x"]}`,
+	}
+	testCases := []testCase{
+		{
+			name:  "merge",
+			args:  []string{"-merge", "a.json", "b.json"},
+			files: files,
+			want:  "a:\n  x:\n    k: 1\n  z: foo\nb:\n  x:\n    k: 2\n",
+		},
+		{
+			name:  "key template",
+			args:  []string{"-merge", "-key-template", "{{.Base}}", "a.json", "b.json"},
+			files: files,
+			want:  "a.json:\n  x:\n    k: 1\n  z: foo\nb.json:\n  x:\n    k: 2\n",
+		},
+		{
+			name:     "duplicate key",
+			args:     []string{"-merge", "-key-template", "x", "a.json", "b.json"},
+			files:    files,
+			wantErr:  "json2yaml: b.json: duplicate key \"x\" (use -key-template)\n",
+			exitCode: exitCodeErr,
+		},
+		{
+			name:  "with path",
+			args:  []string{"-merge", "-path", "/x", "a.json", "b.json"},
+			files: files,
+			want:  "a:\n  k: 1\nb:\n  k: 2\n",
+		},
+		{
+			name:  "with validate",
+			args:  []string{"-merge", "-validate", "schema.json", "a.json", "b.json"},
+			files: files,
+			want:  "a:\n  x:\n    k: 1\n  z: foo\nb:\n  x:\n    k: 2\n",
+		},
+		{
+			name:     "with validate error",
+			args:     []string{"-merge", "-validate", "schema.json", "a.json", "schema.json"},
+			files:    files,
+			want:     "a:\n  x:\n    k: 1\n  z: foo\nschema:\n  type: object\n  required:\n    - x\n",
+			wantErr:  "json2yaml: schema.json: schema validation failed at \"\": missing required property \"x\"\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:  "with tag",
+			args:  []string{"-merge", "-tag", "x.k=!!str", "-tag", "/z=!foo", "a.json", "b.json"},
+			files: files,
+			want:  "a:\n  x:\n    k: !!str 1\n  z: !foo foo\nb:\n  x:\n    k: !!str 2\n",
+		},
+		{
+			name:  "with tag on the value",
+			args:  []string{"-merge", "-key-template", "{{.Path}}*", "-tag", ".=!v", "a.json", "b.json"},
+			files: files,
+			want:  "a.json*: !v\n  x:\n    k: 1\n  z: foo\nb.json*: !v\n  x:\n    k: 2\n",
+		},
+		{
+			name:  "with line width",
+			args:  []string{"-merge", "-line-width", "10", "c.json"},
+			files: map[string]string{"c.json": `{"x":"foo bar baz "}`},
+			want:  "c:\n  x: \"foo\n    bar\n    baz \"\n",
+		},
+		{
+			name:     "with schema",
+			args:     []string{"-merge", "-schema", "schema.json", "a.json"},
+			files:    files,
+			wantErr:  "json2yaml: cannot use -merge with -schema\n",
+			exitCode: exitCodeUsageErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRun(t, tc)
+		})
+	}
+}
+
+func TestRunUsageError(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:    "-o with -write",
+			args:    []string{"-write", "-o", "x.yaml", "a.json"},
+			wantErr: "json2yaml: cannot use -o with -write\n",
+		},
+		{
+			name:    "-check with -diff",
+			args:    []string{"-check", "-diff", "a.json"},
+			wantErr: "json2yaml: cannot use -check with -o, -write, or -diff\n",
+		},
+		{
+			name:    "-diff with -write",
+			args:    []string{"-diff", "-write", "a.json"},
+			wantErr: "json2yaml: cannot use -diff with -o or -write\n",
+		},
+		{
+			name:    "-merge with -check",
+			args:    []string{"-merge", "-check", "a.json"},
+			wantErr: "json2yaml: cannot use -merge with -write, -diff, or -check\n",
+		},
+		{
+			name:    "-serve with input files",
+			args:    []string{"-serve", ":0", "a.json"},
+			wantErr: "json2yaml: cannot use -serve with the input files or the other modes\n",
+		},
+		{
+			name:    "-follow with stdin",
+			args:    []string{"-follow", "-"},
+			wantErr: "json2yaml: cannot use -follow except with one input file\n",
+		},
+		{
+			name:    "-follow with multiple files",
+			args:    []string{"-follow", "a.json", "b.json"},
+			wantErr: "json2yaml: cannot use -follow except with one input file\n",
+		},
+		{
+			name:    "-remove without -write",
+			args:    []string{"-remove", "a.json"},
+			wantErr: "json2yaml: cannot use -remove without -write\n",
+		},
+		{
+			name:    "-write without input files",
+			args:    []string{"-write"},
+			wantErr: "json2yaml: no input files\n",
+		},
+		{
+			name:    "-watch without input files",
+			args:    []string{"-watch"},
+			wantErr: "json2yaml: no input files\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exitCode = exitCodeUsageErr
+			testRun(t, tc)
+		})
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	stdout, stderr, exitCode := runIn(t, t.TempDir(), "", []string{"-version"})
+	if want := name + " " + version + " "; !strings.HasPrefix(stdout, want) {
+		t.Errorf("should write the version %q but got %q", want, stdout)
+	}
+	if stderr != "" || exitCode != exitCodeOK {
+		t.Errorf("should exit with %d but got %d: %s", exitCodeOK, exitCode, stderr)
+	}
+}
+
+func TestRunWrite(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:     "write",
+			args:     []string{"-write", "a.json", "x/b.json.gz"},
+			files:    map[string]string{"a.json": `{"foo":1}`, "x/b.json.gz": gzipString(`[2]`)},
+			wantFile: map[string]string{"a.json": `{"foo":1}`, "a.yaml": "foo: 1\n", "x/b.yaml": "- 2\n"},
+		},
+		{
+			name:     "write and remove",
+			args:     []string{"-write", "-remove", "a.json"},
+			files:    map[string]string{"a.json": `{"foo":1}`},
+			wantFile: map[string]string{"a.yaml": "foo: 1\n"},
+			removed:  []string{"a.json"},
+		},
+		{
+			name:  "write recursively",
+			args:  []string{"-write", "-r", "-jobs", "2", "x", "c.json"},
+			files: map[string]string{"x/a.json": `1`, "x/y/b.json": `2`, "x/z.txt": `3`, "c.json": `4`},
+			wantFile: map[string]string{
+				"x/a.yaml": "1\n", "x/y/b.yaml": "2\n", "c.yaml": "4\n",
+			},
+			removed: []string{"x/z.yaml"},
+		},
+		{
+			name:     "write from file list",
+			args:     []string{"-write", "-files", "-"},
+			files:    map[string]string{"a.json": `1`, "b.json": `2`},
+			input:    "a.json\nb.json\n",
+			wantFile: map[string]string{"a.yaml": "1\n", "b.yaml": "2\n"},
+		},
+		{
+			name:     "write invalid input",
+			args:     []string{"-write", "-remove", "a.json", "b.json"},
+			files:    map[string]string{"a.json": `{"foo":`, "b.json": `2`},
+			wantErr:  "json2yaml: a.json:1:8: unexpected EOF\n",
+			wantFile: map[string]string{"a.json": `{"foo":`, "b.yaml": "2\n"},
+			removed:  []string{"a.yaml", "b.json"},
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:     "write yaml file",
+			args:     []string{"-write", "a.yaml"},
+			files:    map[string]string{"a.yaml": `1`},
+			wantErr:  "json2yaml: a.yaml: cannot overwrite the input file\n",
+			exitCode: exitCodeErr,
+		},
+		{
+			name:     "write file not found",
+			args:     []string{"-write", "a.json"},
+			wantErr:  "json2yaml: stat a.json: no such file or directory\n",
+			exitCode: exitCodeErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRun(t, tc)
+		})
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:  "same",
+			args:  []string{"-diff", "a.json"},
+			files: map[string]string{"a.json": `{"foo":1}`, "a.yaml": "foo: 1\n"},
+		},
+		{
+			name:     "differ",
+			args:     []string{"-diff", "a.json", "b.json"},
+			files:    map[string]string{"a.json": `{"foo":1}`, "a.yaml": "foo: 2\n", "b.json": `1`},
+			want:     "--- a.yaml\n+++ a.json\n@@ -1 +1 @@\n-foo: 2\n+foo: 1\n--- b.yaml\n+++ b.json\n@@ -0,0 +1 @@\n+1\n",
+			exitCode: exitCodeDiff,
+		},
+		{
+			name:     "differ recursively",
+			args:     []string{"-diff", "-r"},
+			files:    map[string]string{"x/a.json": `1`, "x/a.yaml": "1\n", "x/b.json": `2`},
+			want:     "--- x/b.yaml\n+++ x/b.json\n@@ -0,0 +1 @@\n+2\n",
+			exitCode: exitCodeDiff,
+		},
+		{
+			name:     "invalid input",
+			args:     []string{"-diff", "a.json", "b.json"},
+			files:    map[string]string{"a.json": `[1,`, "b.json": `1`},
+			want:     "--- b.yaml\n+++ b.json\n@@ -0,0 +1 @@\n+1\n",
+			wantErr:  "json2yaml: a.json:1:4: unexpected EOF\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:     "yaml file",
+			args:     []string{"-diff", "a.yaml"},
+			files:    map[string]string{"a.yaml": `1`},
+			wantErr:  "json2yaml: a.yaml: cannot compare with the input file\n",
+			exitCode: exitCodeErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRun(t, tc)
+		})
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:  "valid",
+			args:  []string{"-check", "a.json", "b.json.gz"},
+			files: map[string]string{"a.json": `{"foo":1} [2]`, "b.json.gz": gzipString(`3`)},
+		},
+		{
+			name:  "valid stdin",
+			args:  []string{"-check"},
+			input: `{"foo":1}`,
+		},
+		{
+			name:     "invalid",
+			args:     []string{"-check", "-r"},
+			files:    map[string]string{"a.json": "{\n\"foo\":,}", "x/b.json": `1`, "x/c.json": `[1 2]`},
+			wantErr:  "json2yaml: a.json:2:7: invalid character ',' looking for beginning of value\njson2yaml: x/c.json:1:4: invalid character '2' after array element\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:     "invalid and not found",
+			args:     []string{"-check", "a.json", "b.json"},
+			files:    map[string]string{"a.json": `[`},
+			wantErr:  "json2yaml: a.json:1:2: unexpected EOF\njson2yaml: open b.json: no such file or directory\n",
+			exitCode: exitCodeErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRun(t, tc)
+		})
+	}
+}
+
+func TestRunFollow(t *testing.T) {
+	testCase := testCase{
+		args:     []string{"-follow", "-interval", "1ms", "a.json"},
+		files:    map[string]string{"a.json": `{"foo":1} [2] x`},
+		want:     "foo: 1\n---\n- 2\n---\n",
+		wantErr:  "json2yaml: a.json:1:15: invalid character 'x' looking for beginning of value\n",
+		exitCode: exitCodeParseErr,
+	}
+	testRun(t, testCase)
+}
+
+func TestRunURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.json":
+			_, _ = io.WriteString(w, `{"foo":[1,2]}`)
+		case "/b.json":
+			_, _ = io.WriteString(w, `{"foo":`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	testCases := []testCase{
+		{
+			name: "url",
+			args: []string{server.URL + "/a.json"},
+			want: "foo:\n  - 1\n  - 2\n",
+		},
+		{
+			name:     "invalid input",
+			args:     []string{server.URL + "/b.json"},
+			want:     "foo:\n",
+			wantErr:  "json2yaml: " + server.URL + "/b.json:1:8: unexpected EOF\n",
+			exitCode: exitCodeParseErr,
+		},
+		{
+			name:     "not found",
+			args:     []string{server.URL + "/c.json"},
+			wantErr:  "json2yaml: " + server.URL + "/c.json: 404 Not Found\n",
+			exitCode: exitCodeErr,
+		},
+		{
+			name:     "max size",
+			args:     []string{"-max-size", "10", server.URL + "/a.json"},
+			want:     "foo:\n  - 1\n  - \n",
+			wantErr:  "json2yaml: " + server.URL + "/a.json:1:11: response body exceeds 10 bytes\n",
+			exitCode: exitCodeErr,
+		},
+		{
+			name: "max size not exceeded",
+			args: []string{"-max-size", "13", server.URL + "/a.json"},
+			want: "foo:\n  - 1\n  - 2\n",
+		},
+		{
+			name:     "follow",
+			args:     []string{"-follow", server.URL + "/a.json"},
+			wantErr:  "json2yaml: cannot use -follow except with one input file\n",
+			exitCode: exitCodeUsageErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRun(t, tc)
+		})
+	}
+}
+
+func gzipString(s string) string {
+	var sb strings.Builder
+	w := gzip.NewWriter(&sb)
+	_, _ = io.WriteString(w, s)
+	_ = w.Close()
+	return sb.String()
+}
+
+func testRun(t *testing.T, tc testCase) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range tc.files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout, stderr, exitCode := runIn(t, dir, tc.input, tc.args)
+	if tc.usage {
+		if !strings.HasPrefix(stdout, name+" - convert JSON to YAML\n") {
+			t.Errorf("should write the usage but got\n  %q", stdout)
+		}
+	} else if got, want := stdout, tc.want; got != want {
+		t.Errorf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if tc.wantErr == "" {
+		if stderr != "" {
+			t.Errorf("should not write to stderr but got\n  %q", stderr)
+		}
+	} else if !strings.Contains(stderr, tc.wantErr) {
+		t.Errorf("should write to stderr\n  %q\nbut got\n  %q", tc.wantErr, stderr)
+	}
+	if exitCode != tc.exitCode {
+		t.Errorf("should exit with %d but got %d", tc.exitCode, exitCode)
+	}
+	for _, name := range tc.removed {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("should remove %s but got: %v", name, err)
+		}
+	}
+	for name, want := range tc.wantFile {
+		bs, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("should write %s but got: %s", name, err)
+		} else if got := string(bs); got != want {
+			t.Errorf("should write %s\n  %q\nbut got\n  %q", name, want, got)
+		}
+	}
+}
+
+// runIn calls run in the directory with the standard input, and returns the
+// standard output, the standard error, and the exit code.
+func runIn(t *testing.T, dir, input string, args []string) (string, string, int) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	stdin, stdout, stderr := os.Stdin, os.Stdout, os.Stderr
+	defer func() { os.Stdin, os.Stdout, os.Stderr = stdin, stdout, stderr }()
+	files := make([]*os.File, 3)
+	for i := range files {
+		f, err := os.CreateTemp(t.TempDir(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		files[i] = f
+	}
+	if _, err := files[0].WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := files[0].Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	os.Stdin, os.Stdout, os.Stderr = files[0], files[1], files[2]
+	exitCode := run(args)
+	var outputs [2]string
+	for i, f := range files[1:] {
+		bs, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[i] = string(bs)
+	}
+	return outputs[0], outputs[1], exitCode
+}
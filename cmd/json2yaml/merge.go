@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/itchyny/json2yaml"
+)
+
+// mergeKey is the data of the key template of -merge.
+type mergeKey struct {
+	Path string // path of the file
+	Dir  string // directory of the file
+	Base string // name of the file
+	Name string // name of the file without the extensions
+}
+
+func newMergeKey(name string) mergeKey {
+	base := filepath.Base(name)
+	return mergeKey{
+		Path: name,
+		Dir:  filepath.Dir(name),
+		Base: base,
+		Name: strings.TrimSuffix(yamlName(base), ".yaml"),
+	}
+}
+
+// mergeFiles writes a YAML mapping with the values converted from the files,
+// and the keys derived from the file names by the template. The input options
+// are applied to each file, and the output options to the mapping. The tags
+// are applied to the values of the files, with the patterns relative to them.
+func mergeFiles(w io.Writer, names []string, tmpl *template.Template,
+	input, output []json2yaml.Option, tags []tagFlag) (exitCode int) {
+	keys := make([]string, len(names))
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, newMergeKey(name)); err != nil {
+			return failure(exitCode, err)
+		}
+		key := sb.String()
+		if seen[key] {
+			return failure(exitCode, fmt.Errorf("%s: duplicate key %q (use -key-template)", name, key))
+		}
+		keys[i], seen[key] = key, true
+	}
+	output = output[:len(output):len(output)]
+	for _, key := range keys {
+		for _, t := range tags {
+			output = append(output, json2yaml.WithTag(mergeTagPattern(key, t.pattern), t.tag))
+		}
+	}
+	tw := json2yaml.NewTokenWriter(w, output...)
+	defer func() {
+		if err := tw.Close(); err != nil && exitCode == exitCodeOK {
+			exitCode = failure(exitCode, err)
+		}
+	}()
+	if err := tw.BeginObject(); err != nil {
+		return failure(exitCode, err)
+	}
+	for i, name := range names {
+		key, written := keys[i], false
+		if err := convert(io.Discard, name, append(input[:len(input):len(input)],
+			json2yaml.WithEventHandler(func(e json2yaml.Event) error {
+				switch e.Kind {
+				case json2yaml.EventDocumentStart:
+					if written {
+						return errors.New("cannot merge multiple JSON values")
+					}
+					written = true
+					return tw.Key(key)
+				case json2yaml.EventObjectStart:
+					return tw.BeginObject()
+				case json2yaml.EventObjectEnd:
+					return tw.EndObject()
+				case json2yaml.EventArrayStart:
+					return tw.BeginArray()
+				case json2yaml.EventArrayEnd:
+					return tw.EndArray()
+				case json2yaml.EventKey:
+					return tw.Key(e.Value.(string))
+				case json2yaml.EventScalar:
+					return tw.WriteToken(e.Value)
+				}
+				return nil
+			}))); err != nil {
+			// The mapping cannot be continued after a partially written value.
+			return failure(exitCode, err)
+		}
+	}
+	if err := tw.EndObject(); err != nil {
+		return failure(exitCode, err)
+	}
+	return
+}
+
+// mergeTagPattern returns the JSON Pointer matching the values at the pattern
+// of -tag in the value of the key.
+func mergeTagPattern(key, pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("/")
+	sb.WriteString(escapePointer(escapeGlob(key)))
+	if strings.HasPrefix(pattern, "/") {
+		sb.WriteString(pattern)
+	} else if pattern = strings.TrimPrefix(pattern, "."); pattern != "" {
+		for _, k := range strings.Split(pattern, ".") {
+			sb.WriteString("/")
+			sb.WriteString(escapePointer(k))
+		}
+	}
+	return sb.String()
+}
+
+func escapeGlob(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/json2yaml"
+)
+
+// options are the conversion options specified by the flags.
+type options struct {
+	input          []json2yaml.Option // options on reading the input
+	output         []json2yaml.Option // options on writing YAML
+	tags           []tagFlag
+	schemaComments bool
+}
+
+// tagFlag is the value of -tag.
+type tagFlag struct {
+	pattern, tag string
+}
+
+// all returns all the options for the conversion.
+func (o *options) all() []json2yaml.Option {
+	opts := make([]json2yaml.Option, 0, len(o.input)+len(o.output)+len(o.tags))
+	opts = append(append(opts, o.input...), o.output...)
+	for _, t := range o.tags {
+		opts = append(opts, json2yaml.WithTag(t.pattern, t.tag))
+	}
+	return opts
+}
+
+// optionFlags defines the flags for the conversion options.
+func optionFlags(fs *flag.FlagSet, o *options) {
+	fs.Func("path", "convert only the value at the `path` (e.g. spec.template)", func(s string) error {
+		o.input = append(o.input, json2yaml.WithPath(s))
+		return nil
+	})
+	fs.Var(boolFunc(func() {
+		o.input = append(o.input, json2yaml.WithSplitArray())
+	}), "split", "write each element of the array (at -path) as a document")
+	fs.Var(boolFunc(func() {
+		o.input = append(o.input, json2yaml.WithWrapArray())
+	}), "wrap", "write the values in each input (e.g. NDJSON) as one sequence")
+	fs.Var(boolFunc(func() {
+		o.input = append(o.input, json2yaml.WithKubernetesList())
+	}), "k8s-list", "write each item of the Kubernetes List objects as a document")
+	fs.Func("redact", "mask the values of the keys matching the `pattern` (repeatable)", func(s string) error {
+		o.input = append(o.input, json2yaml.WithRedactKeys(s))
+		return nil
+	})
+	fs.Var(boolFunc(func() {
+		o.input = append(o.input, json2yaml.WithExpandJSON())
+	}), "expand-json", "expand JSON embedded in strings")
+	fs.Func("tag", "write the tag on the values at the path (`pattern=tag`, repeatable)", func(s string) error {
+		pattern, tag, ok := strings.Cut(s, "=")
+		if !ok {
+			return errors.New("expected pattern=tag")
+		}
+		o.tags = append(o.tags, tagFlag{pattern, tag})
+		return nil
+	})
+	fs.Func("tag-directive", "write the %TAG directive (`handle=prefix`, repeatable)", func(s string) error {
+		handle, prefix, ok := strings.Cut(s, "=")
+		if !ok {
+			return errors.New("expected handle=prefix")
+		}
+		o.output = append(o.output, json2yaml.WithTagDirective(handle, prefix))
+		return nil
+	})
+	fs.Var(boolFunc(func() {
+		o.input = append(o.input, json2yaml.WithStrictNumbers())
+	}), "strict-numbers", "reject the number literals not delimited from the next value (e.g. 01)")
+	fs.Func("empty", "`policy` on the input without values (ignore, null, or error)", func(s string) error {
+		policy, ok := map[string]json2yaml.EmptyInput{
+			"ignore": json2yaml.EmptyInputIgnore,
+			"null":   json2yaml.EmptyInputNull,
+			"error":  json2yaml.EmptyInputError,
+		}[s]
+		if !ok {
+			return errors.New("expected ignore, null, or error")
+		}
+		o.input = append(o.input, json2yaml.WithEmptyInput(policy))
+		return nil
+	})
+	fs.Func("line-width", "fold the double-quoted strings exceeding the `width`", func(s string) error {
+		width, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		o.output = append(o.output, json2yaml.WithLineWidth(width))
+		return nil
+	})
+	fs.Func("preset", "format the output in the conventional layout of the `name` (helm)", func(s string) error {
+		switch s {
+		case "helm":
+			o.output = append(o.output, json2yaml.WithBlankLines())
+		default:
+			return errors.New("expected helm")
+		}
+		return nil
+	})
+	fs.Func("truncate", "truncate the string values longer than `n` characters", func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		o.output = append(o.output, json2yaml.WithTruncate(n))
+		return nil
+	})
+	fs.Func("header", "write the `comment` at the start of each document", func(s string) error {
+		o.output = append(o.output, json2yaml.WithHeader(s))
+		return nil
+	})
+	fs.Func("schema", "annotate the keys with the descriptions in the JSON Schema `file`", func(s string) error {
+		schema, err := parseSchema(s)
+		if err != nil {
+			return err
+		}
+		o.output = append(o.output, json2yaml.WithSchemaComments(schema))
+		o.schemaComments = true
+		return nil
+	})
+	fs.Func("validate", "validate the input against the JSON Schema `file`", func(s string) error {
+		schema, err := parseSchema(s)
+		if err != nil {
+			return err
+		}
+		o.input = append(o.input, json2yaml.WithSchemaValidation(schema, nil))
+		return nil
+	})
+	fs.Var(boolFunc(func() {
+		o.output = append(o.output, json2yaml.WithDocumentFlush())
+	}), "flush", "flush the output at the end of each document")
+}
+
+func parseSchema(name string) (*json2yaml.Schema, error) {
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return json2yaml.ParseSchema(f)
+}
+
+// boolFunc is a boolean flag calling the function when set to true.
+type boolFunc func()
+
+func (f boolFunc) String() string { return "" }
+
+func (f boolFunc) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err == nil && v {
+		f()
+	}
+	return err
+}
+
+func (f boolFunc) IsBoolFlag() bool { return true }
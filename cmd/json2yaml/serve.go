@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/itchyny/json2yaml"
+)
+
+// serve runs the HTTP server responding with YAML converted from the JSON
+// request bodies.
+func serve(addr string, opts []json2yaml.Option) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           convertHandler(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+func convertHandler(opts []json2yaml.Option) http.Handler {
+	opts = append(opts[:len(opts):len(opts)],
+		json2yaml.WithDecompress(), json2yaml.WithErrorPosition(), json2yaml.WithDocumentFlush())
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		// The server stops reading the request body once the response is
+		// written, unless full duplex is enabled (available since Go 1.21).
+		if d, ok := w.(interface{ EnableFullDuplex() error }); ok {
+			_ = d.EnableFullDuplex()
+		}
+		dw := &documentWriter{w: w}
+		if err := json2yaml.Convert(dw, r.Body, opts...); err != nil {
+			if !dw.flushed {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			// Abort the response to let the client know that it is truncated.
+			fmt.Fprintf(os.Stderr, "%s: %s %s: %s\n", name, r.Method, r.URL.Path, err)
+			panic(http.ErrAbortHandler)
+		}
+		dw.Flush()
+	})
+}
+
+// documentWriter buffers the output until the end of each document, so that
+// the error before the first document is responded with the status code.
+type documentWriter struct {
+	w       http.ResponseWriter
+	buf     bytes.Buffer
+	flushed bool
+}
+
+func (w *documentWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *documentWriter) Flush() {
+	if !w.flushed {
+		w.w.Header().Set("Content-Type", "application/yaml")
+		w.flushed = true
+	}
+	if _, err := w.buf.WriteTo(w.w); err != nil {
+		return
+	}
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
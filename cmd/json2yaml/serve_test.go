@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertHandler(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		status   int
+		want     string
+		wantType string
+		panic    bool
+	}{
+		{
+			name:     "convert",
+			method:   http.MethodPost,
+			body:     `{"foo":[1,2]} {"bar":null}`,
+			status:   http.StatusOK,
+			want:     "# converted\nfoo:\n  - 1\n  - 2\n---\n# converted\nbar: null\n",
+			wantType: "application/yaml",
+		},
+		{
+			name:     "gzip",
+			method:   http.MethodPost,
+			body:     gzipString(`{"foo":1}`),
+			status:   http.StatusOK,
+			want:     "# converted\nfoo: 1\n",
+			wantType: "application/yaml",
+		},
+		{
+			name:     "method not allowed",
+			method:   http.MethodGet,
+			status:   http.StatusMethodNotAllowed,
+			want:     "method not allowed\n",
+			wantType: "text/plain; charset=utf-8",
+		},
+		{
+			name:     "invalid input",
+			method:   http.MethodPost,
+			body:     `{"foo":}`,
+			status:   http.StatusBadRequest,
+			want:     "line 1, column 8: invalid character '}' looking for beginning of value\n",
+			wantType: "text/plain; charset=utf-8",
+		},
+		{
+			name:     "invalid input after the first document",
+			method:   http.MethodPost,
+			body:     `{"foo":1} {"bar":}`,
+			status:   http.StatusOK,
+			want:     "# converted\nfoo: 1\n",
+			wantType: "application/yaml",
+			panic:    true,
+		},
+	}
+	// The handler reports the error after the response is started.
+	stderr := os.Stderr
+	defer func() { os.Stderr = stderr }()
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	os.Stderr = f
+	h := convertHandler([]json2yaml.Option{json2yaml.WithHeader("converted")})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if err := recover(); (err == http.ErrAbortHandler) != tc.panic {
+						t.Errorf("should panic: %t but got %v", tc.panic, err)
+					}
+				}()
+				h.ServeHTTP(rec, req)
+			}()
+			if rec.Code != tc.status {
+				t.Errorf("should respond with status %d but got %d", tc.status, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tc.wantType {
+				t.Errorf("should respond with Content-Type %q but got %q", tc.wantType, got)
+			}
+			if got := rec.Body.String(); got != tc.want {
+				t.Errorf("should respond with\n  %q\nbut got\n  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	urlTimeout time.Duration
+	urlMaxSize int64
+)
+
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// openURL fetches the URL, and returns the response body limited to
+// urlMaxSize bytes.
+func openURL(url string) (io.ReadCloser, error) {
+	client := &http.Client{Timeout: urlTimeout}
+	res, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		res.Body.Close()
+		return nil, fmt.Errorf("%s: %s", url, res.Status)
+	}
+	if urlMaxSize > 0 {
+		return &limitReader{ReadCloser: res.Body, n: urlMaxSize}, nil
+	}
+	return res.Body, nil
+}
+
+// limitReader reads at most n bytes, and returns an error on more bytes.
+type limitReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if r.n < 0 {
+		return 0, fmt.Errorf("response body exceeds %d bytes", urlMaxSize)
+	}
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if r.n -= int64(n); r.n < 0 {
+		return n + int(r.n), fmt.Errorf("response body exceeds %d bytes", urlMaxSize)
+	}
+	return n, err
+}
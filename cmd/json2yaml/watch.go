@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watchFiles calls the function whenever the files, or the .json files in the
+// directories, are modified. It polls the modification times at the interval,
+// and never returns.
+func watchFiles(args []string, interval time.Duration, f func() int) {
+	prev := statFiles(args)
+	f()
+	for {
+		time.Sleep(interval)
+		if curr := statFiles(args); !equalStats(prev, curr) {
+			prev = curr
+			f()
+		}
+	}
+}
+
+// separateRuns returns the function calling f, which writes the separator to
+// w before each call but the first.
+func separateRuns(w io.Writer, separator string, f func() int) func() int {
+	var called bool
+	return func() int {
+		if called {
+			fmt.Fprintln(w, separator)
+		}
+		called = true
+		return f()
+	}
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFiles(args []string) map[string]fileStat {
+	stats := make(map[string]fileStat)
+	for _, arg := range args {
+		_ = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if !d.IsDir() && (path == arg || isJSONFile(path)) {
+				if fi, err := d.Info(); err == nil {
+					stats[path] = fileStat{fi.ModTime(), fi.Size()}
+				}
+			}
+			return nil
+		})
+		if _, err := os.Stat(arg); err != nil {
+			stats[arg] = fileStat{}
+		}
+	}
+	return stats
+}
+
+func equalStats(xs, ys map[string]fileStat) bool {
+	if len(xs) != len(ys) {
+		return false
+	}
+	for k, x := range xs {
+		if y, ok := ys[k]; !ok || !x.modTime.Equal(y.modTime) || x.size != y.size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeparateRuns(t *testing.T) {
+	var sb strings.Builder
+	var runs int
+	f := separateRuns(&sb, "---", func() int {
+		runs++
+		fmt.Fprintf(&sb, "run: %d\n", runs)
+		return runs
+	})
+	for i := 1; i <= 3; i++ {
+		if got := f(); got != i {
+			t.Fatalf("should return %d but got %d", i, got)
+		}
+	}
+	if got, want := sb.String(), "run: 1\n---\nrun: 2\n---\nrun: 3\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+}
+
+func TestStatFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.json": `1`, "x/b.json": `2`, "x/c.txt": `3`} {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "x"), filepath.Join(dir, "y.json")}
+	prev := statFiles(args)
+	if got, want := len(prev), 3; got != want {
+		t.Fatalf("should stat %d files but got %d: %v", want, got, prev)
+	}
+	if !equalStats(prev, statFiles(args)) {
+		t.Fatalf("should not detect changes")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "x", "c.txt"), []byte(`30`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !equalStats(prev, statFiles(args)) {
+		t.Fatalf("should not detect changes of the files other than .json")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "x", "b.json"), []byte(`20`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if equalStats(prev, statFiles(args)) {
+		t.Fatalf("should detect the modified file")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "y.json"), []byte(`4`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	curr := statFiles(args)
+	if equalStats(prev, curr) {
+		t.Fatalf("should detect the created file")
+	}
+	if err := os.Remove(filepath.Join(dir, "a.json")); err != nil {
+		t.Fatal(err)
+	}
+	if equalStats(curr, statFiles(args[:2])) {
+		t.Fatalf("should detect the removed file")
+	}
+}
+
+func TestFollowReader(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "a.json")
+	if err := os.WriteFile(name, []byte(`{"foo":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := &followReader{r: f, interval: time.Millisecond}
+	bs := make([]byte, 64)
+	if n, err := r.Read(bs); err != nil || string(bs[:n]) != `{"foo":1}` {
+		t.Fatalf("should read the file but got %q, %v", bs[:n], err)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = os.WriteFile(name, []byte(`[`), 0o644)
+	}()
+	if _, err := r.Read(bs); err == nil || err.Error() != "file truncated" {
+		t.Fatalf("should raise an error on truncation but got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/itchyny/json2yaml"
+)
+
+// writeFiles runs writeFile for the files with the workers, and returns the
+// exit code.
+func writeFiles(names []string, remove bool, jobs int, opts []json2yaml.Option) int {
+	if jobs < 1 {
+		jobs = 1
+	}
+	ch := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	exitCode := exitCodeOK
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range ch {
+				if err := writeFile(n, remove, opts); err != nil {
+					mu.Lock()
+					exitCode = failure(exitCode, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, n := range names {
+		ch <- n
+	}
+	close(ch)
+	wg.Wait()
+	return exitCode
+}
+
+// writeFile converts the JSON file to the YAML file next to it. The output is
+// written to a temporary file and renamed, so that an interrupted run does not
+// leave a truncated file.
+func writeFile(name string, remove bool, opts []json2yaml.Option) (err error) {
+	dst := yamlName(name)
+	if dst == name {
+		return errors.New(name + ": cannot overwrite the input file")
+	}
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if err = convert(f, name, opts); err != nil {
+		return err
+	}
+	if err = f.Chmod(fi.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), dst); err != nil {
+		return err
+	}
+	if remove {
+		return os.Remove(name)
+	}
+	return nil
+}
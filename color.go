@@ -0,0 +1,37 @@
+package json2yaml
+
+import "encoding/json"
+
+// Colors of the output, in ANSI escape sequences.
+const (
+	colorReset   = "\x1b[0m"
+	colorKey     = "\x1b[34;1m"
+	colorString  = "\x1b[32m"
+	colorNumber  = "\x1b[36m"
+	colorLiteral = "\x1b[33m"
+	colorMarker  = "\x1b[90m"
+)
+
+// colorOf returns the color of the object key or the scalar value.
+func (c *converter) colorOf(v any) string {
+	if c.stack[len(c.stack)-1] == '{' {
+		return colorKey
+	}
+	switch v.(type) {
+	case string:
+		return colorString
+	case json.Number, float64:
+		return colorNumber
+	default:
+		return colorLiteral
+	}
+}
+
+// writeMarker writes the document marker (--- or ...) in a line.
+func (c *converter) writeMarker(marker string) {
+	if c.color {
+		c.buf.WriteString(colorMarker + marker + colorReset + "\n")
+	} else {
+		c.buf.WriteString(marker + "\n")
+	}
+}
@@ -0,0 +1,45 @@
+package json2yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithColor(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "scalars",
+			src:  `null true 128 "foo"`,
+			want: "\x1b[33mnull\x1b[0m\n\x1b[90m---\x1b[0m\n\x1b[33mtrue\x1b[0m\n" +
+				"\x1b[90m---\x1b[0m\n\x1b[36m128\x1b[0m\n\x1b[90m---\x1b[0m\n\x1b[32mfoo\x1b[0m\n",
+		},
+		{
+			name: "object and array",
+			src:  `{"foo":[1,"bar",{}],"baz":{"qux":null}}`,
+			want: "\x1b[34;1mfoo\x1b[0m:\n  - \x1b[36m1\x1b[0m\n  - \x1b[32mbar\x1b[0m\n  - {}\n" +
+				"\x1b[34;1mbaz\x1b[0m:\n  \x1b[34;1mqux\x1b[0m: \x1b[33mnull\x1b[0m\n",
+		},
+		{
+			name: "multi-line string",
+			src:  `{"foo":"bar\nbaz\n"}`,
+			want: "\x1b[34;1mfoo\x1b[0m: \x1b[32m|\n  bar\n  baz\x1b[0m\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithColor()); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package json2yaml
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompressReader decompresses the input if it starts with the magic number
+// of gzip, and passes through the input otherwise.
+type decompressReader struct {
+	r io.Reader
+	z io.Reader
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	if r.z == nil {
+		br := bufio.NewReader(r.r)
+		r.z = br
+		if bs, _ := br.Peek(len(gzipMagic)); bytes.Equal(bs, gzipMagic) {
+			zr, err := gzip.NewReader(br)
+			if err != nil {
+				return 0, err
+			}
+			r.z = zr
+		}
+	}
+	return r.z.Read(p)
+}
@@ -0,0 +1,82 @@
+package json2yaml_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func gzipString(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestConvertWithDecompress(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "gzip",
+			src:  gzipString(t, `{"foo":[1,2]} "bar"`),
+			want: "foo:\n  - 1\n  - 2\n---\nbar\n",
+		},
+		{
+			name: "concatenated gzip",
+			src:  gzipString(t, `{"foo":1}`) + gzipString(t, `{"bar":2}`),
+			want: "foo: 1\n---\nbar: 2\n",
+		},
+		{
+			name: "plain",
+			src:  `{"foo":1}`,
+			want: "foo: 1\n",
+		},
+		{
+			name: "empty",
+			src:  ``,
+			want: "",
+		},
+		{
+			name: "broken gzip",
+			src:  gzipString(t, `{"foo":1}`)[:12],
+			want: "",
+			err:  "unexpected EOF",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithDecompress())
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("should raise an error %q but got %v", tc.err, err)
+				}
+			}
+			if tc.err == "" {
+				if err := json2yaml.Validate(strings.NewReader(tc.src), json2yaml.WithDecompress()); err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			}
+		})
+	}
+}
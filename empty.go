@@ -0,0 +1,19 @@
+package json2yaml
+
+import "errors"
+
+// EmptyInput is the policy on the input without JSON values.
+type EmptyInput int
+
+const (
+	// EmptyInputIgnore writes nothing on the empty input.
+	EmptyInputIgnore EmptyInput = iota
+	// EmptyInputNull writes a null document on the empty input.
+	EmptyInputNull
+	// EmptyInputError returns ErrEmptyInput on the empty input.
+	EmptyInputError
+)
+
+// ErrEmptyInput is the error of the input without JSON values, returned with
+// EmptyInputError.
+var ErrEmptyInput = errors.New("empty input")
@@ -0,0 +1,103 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithEmptyInput(t *testing.T) {
+	testCases := []struct {
+		name   string
+		src    string
+		policy json2yaml.EmptyInput
+		opts   []json2yaml.Option
+		want   string
+		err    error
+	}{
+		{
+			name:   "ignore",
+			src:    "",
+			policy: json2yaml.EmptyInputIgnore,
+			want:   "",
+		},
+		{
+			name:   "null",
+			src:    " \n\t",
+			policy: json2yaml.EmptyInputNull,
+			want:   "null\n",
+		},
+		{
+			name:   "null with header",
+			src:    "",
+			policy: json2yaml.EmptyInputNull,
+			opts:   []json2yaml.Option{json2yaml.WithHeader("DO NOT EDIT.")},
+			want:   "# DO NOT EDIT.\nnull\n",
+		},
+		{
+			name:   "error",
+			src:    "\n",
+			policy: json2yaml.EmptyInputError,
+			want:   "",
+			err:    json2yaml.ErrEmptyInput,
+		},
+		{
+			name:   "not empty",
+			src:    `{"foo":1}`,
+			policy: json2yaml.EmptyInputError,
+			want:   "foo: 1\n",
+		},
+		{
+			name:   "nothing at path",
+			src:    `{"foo":1}`,
+			policy: json2yaml.EmptyInputError,
+			opts:   []json2yaml.Option{json2yaml.WithPath("bar")},
+			want:   "",
+		},
+		{
+			name:   "empty list",
+			src:    `{"kind":"List","items":[]}`,
+			policy: json2yaml.EmptyInputError,
+			opts:   []json2yaml.Option{json2yaml.WithKubernetesList()},
+			want:   "",
+		},
+	}
+	for _, tc := range testCases {
+		for name, convert := range testConverters {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				var sb strings.Builder
+				err := convert(&sb, tc.src, append(tc.opts, json2yaml.WithEmptyInput(tc.policy))...)
+				if got, want := sb.String(), tc.want; got != want {
+					t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+				}
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("should raise an error %v but got %v", tc.err, err)
+				}
+			})
+		}
+	}
+}
+
+// testConverters are the entry points converting the whole input.
+var testConverters = map[string]func(io.Writer, string, ...json2yaml.Option) error{
+	"Convert": func(w io.Writer, src string, opts ...json2yaml.Option) error {
+		return json2yaml.Convert(w, strings.NewReader(src), opts...)
+	},
+	"NewReader": func(w io.Writer, src string, opts ...json2yaml.Option) error {
+		_, err := io.Copy(w, json2yaml.NewReader(strings.NewReader(src), opts...))
+		return err
+	},
+	"NewStream": func(w io.Writer, src string, opts ...json2yaml.Option) error {
+		s := json2yaml.NewStream(w, opts...)
+		for i := 0; i < len(src); i++ {
+			if err := s.Push([]byte(src[i : i+1])); err != nil {
+				_ = s.Close()
+				return err
+			}
+		}
+		return s.Close()
+	},
+}
@@ -0,0 +1,49 @@
+package json2yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Marshal returns the YAML encoding of v. The value is encoded in the same
+// way as json.Marshal, and then converted to YAML.
+func Marshal(v any, opts ...Option) ([]byte, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertBytes(bs, opts...)
+}
+
+// MarshalYAML returns the YAML encoding of v, in the same way as Marshal without
+// options. It has the same signature as yaml.Marshal, so that it can replace it
+// for the types annotated only with the json struct tags.
+func MarshalYAML(v any) ([]byte, error) {
+	return Marshal(v)
+}
+
+// Encoder writes Go values as YAML documents to a stream.
+type Encoder struct {
+	w   *DocumentWriter
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// NewEncoder returns a new Encoder writing YAML to w.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	e := &Encoder{w: NewDocumentWriter(w, opts...)}
+	e.enc = json.NewEncoder(&e.buf)
+	return e
+}
+
+// Encode writes the YAML encoding of v to the stream, separating from the
+// previous documents. The value is encoded in the same way as json.Marshal,
+// so the struct field tags, omitempty, and MarshalJSON methods are honored.
+func (e *Encoder) Encode(v any) error {
+	e.buf.Reset()
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	return e.w.Convert(&e.buf)
+}
@@ -0,0 +1,161 @@
+package json2yaml_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/json2yaml"
+)
+
+type encoderTestValue struct {
+	Name    string            `json:"name"`
+	Tags    []string          `json:"tags,omitempty"`
+	Labels  map[string]string `json:"labels"`
+	Created time.Time         `json:"created"`
+	Raw     json.RawMessage   `json:"raw,omitempty"`
+}
+
+func TestMarshal(t *testing.T) {
+	got, err := json2yaml.Marshal(&encoderTestValue{
+		Name: "foo", Tags: []string{"a", "b"}, Raw: json.RawMessage(`"x: y"`),
+	})
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	want := `name: foo
+tags:
+  - a
+  - b
+labels: null
+created: "0001-01-01T00:00:00Z"
+raw: "x: y"
+`
+	if string(got) != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, string(got))
+	}
+	if _, err = json2yaml.Marshal(make(chan int)); err == nil {
+		t.Fatalf("should raise an error but got no error")
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	var marshal func(any) ([]byte, error) = json2yaml.MarshalYAML
+	got, err := marshal(map[string]any{
+		"value": &encoderTestValue{Name: "foo", Labels: map[string]string{"b": "", "a": "1"}},
+		"list":  []int{1, 2},
+	})
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	want := `list:
+  - 1
+  - 2
+value:
+  name: foo
+  labels:
+    a: "1"
+    b: ""
+  created: "0001-01-01T00:00:00Z"
+`
+	if string(got) != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, string(got))
+	}
+	if _, err = marshal(func() {}); err == nil {
+		t.Fatalf("should raise an error but got no error")
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var sb strings.Builder
+	enc := json2yaml.NewEncoder(&sb)
+	for _, v := range []any{
+		encoderTestValue{
+			Name:    "foo",
+			Labels:  map[string]string{"z": "1", "a": "true"},
+			Created: time.Date(2022, 8, 4, 12, 13, 14, 0, time.UTC),
+			Raw:     json.RawMessage(`[1.0,{}]`),
+		},
+		[]any{nil, 1.5, "a\nb"},
+		&encoderTestValue{Name: "bar", Tags: []string{"x"}},
+	} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatalf("should not raise an error but got: %s", err)
+		}
+	}
+	want := `name: foo
+labels:
+  a: "true"
+  z: "1"
+created: "2022-08-04T12:13:14Z"
+raw:
+  - 1.0
+  - {}
+---
+- null
+- 1.5
+- |-
+  a
+  b
+---
+name: bar
+tags:
+  - x
+labels: null
+created: "0001-01-01T00:00:00Z"
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+}
+
+func TestEncoderError(t *testing.T) {
+	var sb strings.Builder
+	err := json2yaml.NewEncoder(&sb).Encode(map[string]any{"x": func() {}})
+	if err == nil {
+		t.Fatalf("should raise an error but got no error")
+	}
+	if got := sb.String(); got != "" {
+		t.Fatalf("should not write but got %q", got)
+	}
+}
+
+func TestEncoderWriteError(t *testing.T) {
+	sb := &limitWriter{n: 8000}
+	enc := json2yaml.NewEncoder(sb)
+	var err error
+	for _, v := range []string{strings.Repeat("x", 5000), strings.Repeat("y", 5000)} {
+		if err = enc.Encode(v); err != nil {
+			break
+		}
+	}
+	var werr *json2yaml.WriteError
+	if !errors.As(err, &werr) {
+		t.Fatalf("should raise *json2yaml.WriteError but got %v", err)
+	}
+	if got, want := werr.Written, int64(sb.Len()); got != want {
+		t.Fatalf("should report %d bytes written but got %d", want, got)
+	}
+}
+
+func ExampleEncoder() {
+	enc := json2yaml.NewEncoder(os.Stdout)
+	for _, v := range []any{
+		map[string]any{"Hello": "world!"},
+		[]int{1, 2, 3},
+	} {
+		if err := enc.Encode(v); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	// Output:
+	// Hello: world!
+	// ---
+	// - 1
+	// - 2
+	// - 3
+}
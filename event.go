@@ -0,0 +1,91 @@
+package json2yaml
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// EventKind represents the kind of Event.
+type EventKind int
+
+// Event kinds.
+const (
+	EventDocumentStart EventKind = iota + 1
+	EventDocumentEnd
+	EventObjectStart
+	EventObjectEnd
+	EventArrayStart
+	EventArrayEnd
+	EventKey
+	EventScalar
+)
+
+// String implements fmt.Stringer.
+func (k EventKind) String() string {
+	switch k {
+	case EventDocumentStart:
+		return "DocumentStart"
+	case EventDocumentEnd:
+		return "DocumentEnd"
+	case EventObjectStart:
+		return "ObjectStart"
+	case EventObjectEnd:
+		return "ObjectEnd"
+	case EventArrayStart:
+		return "ArrayStart"
+	case EventArrayEnd:
+		return "ArrayEnd"
+	case EventKey:
+		return "Key"
+	case EventScalar:
+		return "Scalar"
+	default:
+		return "EventKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Event represents an event of the conversion.
+type Event struct {
+	Kind EventKind
+	// Value is the object key for EventKey, or the scalar value for
+	// EventScalar; nil, bool, float64, json.Number, or string.
+	Value any
+	// Depth is the number of the enclosing objects and arrays.
+	Depth int
+}
+
+// Walk reads JSON from r and calls f for each event, without writing YAML.
+func Walk(r io.Reader, f func(Event) error) error {
+	return Convert(io.Discard, r, WithEventHandler(f))
+}
+
+// handleToken calls the event handler for the token, before it is written.
+func (c *converter) handleToken(token json.Token) error {
+	depth := len(c.stack) - 1
+	if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+		kind := EventObjectEnd
+		if delim == ']' {
+			kind = EventArrayEnd
+		}
+		return c.handler(Event{Kind: kind, Depth: depth - 1})
+	}
+	if depth == 0 {
+		if err := c.handler(Event{Kind: EventDocumentStart}); err != nil {
+			return err
+		}
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		kind := EventObjectStart
+		if token == '[' {
+			kind = EventArrayStart
+		}
+		return c.handler(Event{Kind: kind, Depth: depth})
+	case string:
+		if c.stack[depth] == '{' || c.stack[depth] == ':' && c.pending == pendingNext {
+			return c.handler(Event{Kind: EventKey, Value: token, Depth: depth})
+		}
+	}
+	return c.handler(Event{Kind: EventScalar, Value: token, Depth: depth})
+}
@@ -0,0 +1,63 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestWalk(t *testing.T) {
+	var got []string
+	err := json2yaml.Walk(
+		strings.NewReader(`{"foo":[1,{"bar":null}],"baz":{}} "qux" []`),
+		func(ev json2yaml.Event) error {
+			s := fmt.Sprintf("%d:%s", ev.Depth, ev.Kind)
+			if ev.Value != nil {
+				s += fmt.Sprintf(":%v", ev.Value)
+			}
+			got = append(got, s)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	want := []string{
+		"0:DocumentStart", "0:ObjectStart", "1:Key:foo", "1:ArrayStart", "2:Scalar:1",
+		"2:ObjectStart", "3:Key:bar", "3:Scalar", "2:ObjectEnd", "1:ArrayEnd",
+		"1:Key:baz", "1:ObjectStart", "1:ObjectEnd", "0:ObjectEnd", "0:DocumentEnd",
+		"0:DocumentStart", "0:Scalar:qux", "0:DocumentEnd",
+		"0:DocumentStart", "0:ArrayStart", "0:ArrayEnd", "0:DocumentEnd",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("should call\n  %v\nbut got\n  %v", want, got)
+	}
+}
+
+func TestConvertWithEventHandler(t *testing.T) {
+	var sb strings.Builder
+	var keys []string
+	err := json2yaml.Convert(&sb, strings.NewReader(`{"foo":1,"password":"x","bar":2}`),
+		json2yaml.WithEventHandler(func(ev json2yaml.Event) error {
+			if ev.Kind == json2yaml.EventKey {
+				if ev.Value == "password" {
+					return errors.New("found password")
+				}
+				keys = append(keys, ev.Value.(string))
+			}
+			return nil
+		}))
+	if err == nil || err.Error() != "found password" {
+		t.Fatalf("should raise an error %q but got %v", "found password", err)
+	}
+	if got, want := sb.String(), "foo: 1\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if want := []string{"foo"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("should call with keys %v but got %v", want, keys)
+	}
+}
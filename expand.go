@@ -0,0 +1,32 @@
+package json2yaml
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// isEmbeddedJSON reports whether the string is a JSON object or array.
+func isEmbeddedJSON(s string) bool {
+	s = strings.TrimLeft(s, " \t\n\r")
+	return s != "" && (s[0] == '{' || s[0] == '[') && json.Valid([]byte(s))
+}
+
+// putEmbeddedJSON passes the tokens of the JSON in the string to putToken.
+func (c *converter) putEmbeddedJSON(s string) (bool, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var written bool
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return written, nil
+			}
+			return false, err
+		}
+		if written, err = c.putToken(token); err != nil {
+			return false, err
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package json2yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithExpandJSON(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		opts []json2yaml.Option
+		want string
+	}{
+		{
+			name: "object and array",
+			src:  `{"msg":"{\"a\":1,\"b\":[true,null]}","list":" [1, \"x\"] ","empty":"{}"}`,
+			want: "msg:\n  a: 1\n  b:\n    - true\n    - null\nlist:\n  - 1\n  - x\nempty: {}\n",
+		},
+		{
+			name: "nested",
+			src:  `["{\"payload\":\"[\\\"{}\\\"]\"}"]`,
+			want: "- payload:\n    - {}\n",
+		},
+		{
+			name: "top level",
+			src:  `"{\"a\":\"b\"}" "[]"`,
+			want: "a: b\n---\n[]\n",
+		},
+		{
+			name: "not expanded",
+			src:  `{"{\"key\":1}":"1","b":"true","c":"\"[]\"","d":"{invalid}","e":"[1] [2]"}`,
+			want: `"{\"key\":1}": "1"
+b: "true"
+c: "\"[]\""
+d: "{invalid}"
+e: "[1] [2]"
+`,
+		},
+		{
+			name: "with redaction",
+			src:  `{"body":"{\"password\":\"x\",\"user\":\"y\"}"}`,
+			opts: []json2yaml.Option{json2yaml.WithRedactKeys("password")},
+			want: "body:\n  password: \"***\"\n  user: \"y\"\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			opts := append([]json2yaml.Option{json2yaml.WithExpandJSON()}, tc.opts...)
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src), opts...); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package json2yaml
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// OutputFS is a file system to write the files converted by ConvertFS.
+type OutputFS interface {
+	// Create creates the file of the slash-separated name.
+	Create(name string) (io.WriteCloser, error)
+}
+
+// OutputDir returns an OutputFS creating the files under the directory. The
+// parent directories of the files are created as needed.
+func OutputDir(dir string) OutputFS {
+	return outputDir(dir)
+}
+
+type outputDir string
+
+func (dir outputDir) Create(name string) (io.WriteCloser, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	name = filepath.Join(string(dir), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(name)
+}
+
+// ConvertFS converts the JSON files in fsys matching the pattern (e.g.
+// "config/*.json"), and writes the YAML files to out. The name of the output
+// file is the name of the input file with the extension replaced with ".yaml".
+// The pattern syntax is the same as in path.Match, except that ** matches zero
+// or more directories (e.g. "**/*.json" matches the files in the tree).
+func ConvertFS(out OutputFS, fsys fs.FS, pattern string, opts ...Option) error {
+	names, err := globFS(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := convertFile(out, fsys, name, opts); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// globFS returns the names of the files matching the pattern. It walks the
+// file system only if the pattern contains **.
+func globFS(fsys fs.FS, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	patterns := strings.Split(pattern, "/")
+	var names []string
+	for _, p := range patterns {
+		if p != "**" {
+			continue
+		}
+		err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && matchFilePath(patterns, strings.Split(name, "/")) {
+				names = append(names, name)
+			}
+			return nil
+		})
+		return names, err
+	}
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range matches {
+		fi, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// matchFilePath reports whether the path elements match the patterns, where **
+// matches zero or more elements.
+func matchFilePath(patterns, elems []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchFilePath(patterns[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(patterns[0], elems[0]); !matched {
+			return false
+		}
+		patterns, elems = patterns[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
+// convertFile converts the file into the memory, so that the output file is
+// not created on errors.
+func convertFile(out OutputFS, fsys fs.FS, name string, opts []Option) (err error) {
+	bs, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	bs, err = ConvertBytes(bs, opts...)
+	if err != nil {
+		return err
+	}
+	w, err := out.Create(strings.TrimSuffix(name, path.Ext(name)) + ".yaml")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	_, err = w.Write(bs)
+	return err
+}
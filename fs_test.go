@@ -0,0 +1,169 @@
+package json2yaml_test
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/itchyny/json2yaml"
+)
+
+type mapOutputFS map[string]*strings.Builder
+
+func (fsys mapOutputFS) Create(name string) (io.WriteCloser, error) {
+	sb := new(strings.Builder)
+	fsys[name] = sb
+	return nopCloser{sb}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func TestConvertFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.json":            {Data: []byte(`{"foo":1}`)},
+		"b.txt":             {Data: []byte(`bar`)},
+		"config/c.json":     {Data: []byte(`[1,2]`)},
+		"config/d.json":     {Data: []byte(`{"bar":{"baz":null}} "qux"`)},
+		"config/e/f.json":   {Data: []byte(`{}`)},
+		"config/dir.json/g": {Data: []byte(`{}`)},
+		"invalid/h.json":    {Data: []byte(`{"h":`)},
+	}
+	testCases := []struct {
+		pattern string
+		want    map[string]string
+		err     string
+	}{
+		{
+			pattern: "*.json",
+			want:    map[string]string{"a.yaml": "foo: 1\n"},
+		},
+		{
+			pattern: "config/*.json",
+			want: map[string]string{
+				"config/c.yaml": "- 1\n- 2\n",
+				"config/d.yaml": "bar:\n  baz: null\n---\nqux\n",
+			},
+		},
+		{
+			pattern: "config/**/*.json",
+			want: map[string]string{
+				"config/c.yaml":   "- 1\n- 2\n",
+				"config/d.yaml":   "bar:\n  baz: null\n---\nqux\n",
+				"config/e/f.yaml": "{}\n",
+			},
+		},
+		{
+			pattern: "**/f.json",
+			want:    map[string]string{"config/e/f.yaml": "{}\n"},
+		},
+		{
+			pattern: "*",
+			want:    map[string]string{"a.yaml": "foo: 1\n"},
+			err:     "b.txt: invalid character 'b' looking for beginning of value",
+		},
+		{
+			pattern: "**/*.json",
+			want: map[string]string{
+				"a.yaml":          "foo: 1\n",
+				"config/c.yaml":   "- 1\n- 2\n",
+				"config/d.yaml":   "bar:\n  baz: null\n---\nqux\n",
+				"config/e/f.yaml": "{}\n",
+			},
+			err: "invalid/h.json: unexpected EOF",
+		},
+		{
+			pattern: "[",
+			err:     "syntax error in pattern",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			out := mapOutputFS{}
+			err := json2yaml.ConvertFS(out, fsys, tc.pattern)
+			got := make(map[string]string)
+			for name, sb := range out {
+				got[name] = sb.String()
+			}
+			if tc.want == nil {
+				tc.want = map[string]string{}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", tc.want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("should raise an error %q but got %v", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+// errorDirFS is a file system failing to open the directory.
+type errorDirFS struct {
+	fsys fs.FS
+	dir  string
+}
+
+func (fsys errorDirFS) Open(name string) (fs.File, error) {
+	if name == fsys.dir {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return fsys.fsys.Open(name)
+}
+
+func TestConvertFSWithoutWalk(t *testing.T) {
+	fsys := errorDirFS{fstest.MapFS{
+		"config/a.json":  {Data: []byte(`{"foo":1}`)},
+		"config/b":       {Data: []byte(`{"bar":2}`)},
+		"secret/c.json":  {Data: []byte(`{}`)},
+		"config/d/.keep": {},
+	}, "secret"}
+	out := mapOutputFS{}
+	if err := json2yaml.ConvertFS(out, fsys, "config/*"); err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if got, want := len(out), 2; got != want {
+		t.Fatalf("should write %d files but got %d", want, got)
+	}
+	if got, want := out["config/a.yaml"].String(), "foo: 1\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	err := json2yaml.ConvertFS(mapOutputFS{}, fsys, "**/*.json")
+	if want := "open secret: permission denied"; err == nil || err.Error() != want {
+		t.Fatalf("should raise an error %q but got %v", want, err)
+	}
+}
+
+func TestOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{"config/a.json": {Data: []byte(`{"foo":1}`)}}
+	if err := json2yaml.ConvertFS(json2yaml.OutputDir(dir), fsys, "*/*.json"); err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	bs, err := os.ReadFile(filepath.Join(dir, "config", "a.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(bs), "foo: 1\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if _, err := json2yaml.OutputDir(dir).Create("../a.yaml"); err == nil {
+		t.Fatalf("should raise an error for an invalid path")
+	}
+}
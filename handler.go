@@ -0,0 +1,116 @@
+package json2yaml
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Handler returns a handler which converts the JSON responses of next to YAML
+// when the request accepts application/yaml. The response is converted if its
+// Content-Type is application/json (or a +json type), and the other responses
+// are written as is. The response is streamed through the converter; use
+// WithDocumentFlush to flush the response at the end of each document. If the
+// conversion fails after the response is started, the handler aborts it by
+// panicking with http.ErrAbortHandler.
+func Handler(next http.Handler, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if !acceptsYAML(r.Header.Values("Accept")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.Clone(r.Context())
+		r.Header.Set("Accept", "application/json")
+		rw := &responseWriter{ResponseWriter: w, opts: opts}
+		panicking := true
+		defer func() {
+			// Close the converting writer even if next panics, so that the
+			// converter does not wait for the input forever.
+			if rw.w != nil {
+				if err := rw.w.Close(); err != nil && !panicking {
+					panic(http.ErrAbortHandler)
+				}
+			}
+		}()
+		next.ServeHTTP(rw, r)
+		panicking = false
+	})
+}
+
+func acceptsYAML(values []string) bool {
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			typ, params, err := mime.ParseMediaType(s)
+			if err != nil || typ != "application/yaml" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				return false
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func isJSONType(s string) bool {
+	typ, _, err := mime.ParseMediaType(s)
+	return err == nil && (typ == "application/json" || strings.HasSuffix(typ, "+json"))
+}
+
+// responseWriter converts the JSON response to YAML.
+type responseWriter struct {
+	http.ResponseWriter
+	opts        []Option
+	wroteHeader bool
+	w           io.WriteCloser // converting writer, nil if not converting
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	if status >= http.StatusOK {
+		w.wroteHeader = true
+		h := w.Header()
+		if status != http.StatusNoContent && status != http.StatusNotModified &&
+			isJSONType(h.Get("Content-Type")) {
+			h.Set("Content-Type", "application/yaml")
+			h.Del("Content-Length")
+			w.w = NewWriter(w.ResponseWriter, w.opts...)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.w != nil {
+		return w.w.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush flushes the response unless converting, in which case the response
+// is written by the converter concurrently.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.w != nil {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
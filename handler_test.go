@@ -0,0 +1,158 @@
+package json2yaml_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestHandler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		accept      string
+		contentType string
+		status      int
+		body        string
+		want        string
+		wantType    string
+		panic       bool
+	}{
+		{
+			name:        "accept yaml",
+			accept:      "application/yaml",
+			contentType: "application/json",
+			status:      http.StatusOK,
+			body:        `{"foo":[1,2]} {"bar":null}`,
+			want:        "foo:\n  - 1\n  - 2\n---\nbar: null\n",
+			wantType:    "application/yaml",
+		},
+		{
+			name:        "accept multiple types",
+			accept:      "text/html, application/yaml;q=0.9, */*;q=0.1",
+			contentType: "application/problem+json; charset=utf-8",
+			status:      http.StatusNotFound,
+			body:        `{"title":"Not Found"}`,
+			want:        "title: Not Found\n",
+			wantType:    "application/yaml",
+		},
+		{
+			name:        "accept json",
+			accept:      "application/json",
+			contentType: "application/json",
+			status:      http.StatusOK,
+			body:        `{"foo":1}`,
+			want:        `{"foo":1}`,
+			wantType:    "application/json",
+		},
+		{
+			name:        "yaml not acceptable",
+			accept:      "application/yaml;q=0",
+			contentType: "application/json",
+			status:      http.StatusOK,
+			body:        `{"foo":1}`,
+			want:        `{"foo":1}`,
+			wantType:    "application/json",
+		},
+		{
+			name:        "not json response",
+			accept:      "application/yaml",
+			contentType: "text/plain",
+			status:      http.StatusOK,
+			body:        `{"foo":1}`,
+			want:        `{"foo":1}`,
+			wantType:    "text/plain",
+		},
+		{
+			name:        "no content",
+			accept:      "application/yaml",
+			contentType: "application/json",
+			status:      http.StatusNoContent,
+			wantType:    "application/json",
+		},
+		{
+			name:        "invalid json",
+			accept:      "application/yaml",
+			contentType: "application/json",
+			status:      http.StatusOK,
+			body:        `{"foo":1,}`,
+			want:        "foo: 1\n",
+			wantType:    "application/yaml",
+			panic:       true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := json2yaml.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept"); tc.accept == "application/yaml" && got != "application/json" {
+					t.Errorf("should request application/json but got %q", got)
+				}
+				w.Header().Set("Content-Type", tc.contentType)
+				w.Header().Set("Content-Length", strconv.Itoa(len(tc.body)))
+				w.WriteHeader(tc.status)
+				_, _ = io.WriteString(w, tc.body)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if err := recover(); (err == http.ErrAbortHandler) != tc.panic {
+						t.Errorf("should panic: %t but got %v", tc.panic, err)
+					}
+				}()
+				h.ServeHTTP(rec, req)
+			}()
+			if rec.Code != tc.status {
+				t.Errorf("should respond with status %d but got %d", tc.status, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tc.wantType {
+				t.Errorf("should respond with Content-Type %q but got %q", tc.wantType, got)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("should respond with Vary %q but got %q", "Accept", got)
+			}
+			if got := rec.Body.String(); got != tc.want {
+				t.Errorf("should respond with\n  %q\nbut got\n  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	h := json2yaml.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"foo":1,"bar":`)
+		panic("oops")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/yaml")
+	rec := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if err := recover(); err != "oops" {
+				t.Errorf("should panic with %q but got %v", "oops", err)
+			}
+		}()
+		h.ServeHTTP(rec, req)
+	}()
+	if got, want := rec.Body.String(), "foo: 1\nbar:\n"; got != want {
+		t.Errorf("should respond with\n  %q\nbut got\n  %q", want, got)
+	}
+}
+
+func TestHandlerUnwrap(t *testing.T) {
+	rec := httptest.NewRecorder()
+	h := json2yaml.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok || u.Unwrap() != rec {
+			t.Errorf("should unwrap to the underlying ResponseWriter")
+		}
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/yaml")
+	h.ServeHTTP(rec, req)
+}
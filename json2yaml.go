@@ -5,38 +5,253 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 )
 
 // Convert reads JSON from r and writes YAML to w.
-func Convert(w io.Writer, r io.Reader) error {
-	return (&converter{w, new(bytes.Buffer), []byte{'.'}, 0}).convert(r)
+func Convert(w io.Writer, r io.Reader, opts ...Option) error {
+	return newConverter(w, opts).convert(r)
+}
+
+// WriteError is an error of writing the YAML output, with the number of bytes
+// successfully written before the failure.
+type WriteError struct {
+	Written int64
+	Err     error
+}
+
+func (err *WriteError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *WriteError) Unwrap() error {
+	return err.Err
+}
+
+// ConvertAll reads JSON from the readers and writes YAML to w, as one stream of
+// documents. Each reader is converted separately, so the values are separated
+// at the end of each reader even without trailing white spaces.
+func ConvertAll(w io.Writer, rs []io.Reader, opts ...Option) error {
+	dw := NewDocumentWriter(w, opts...)
+	for _, r := range rs {
+		if err := dw.Convert(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertDecoder converts the next JSON value read by dec to YAML and writes
+// it to w. This is useful to convert a part of a larger JSON stream, continuing
+// from the current position of the decoder. It returns io.EOF if there is no
+// value to read. Note that the numbers are converted from float64 values,
+// unless dec.UseNumber is called beforehand.
+func ConvertDecoder(w io.Writer, dec *json.Decoder, opts ...Option) error {
+	if !dec.More() {
+		return io.EOF
+	}
+	c := newConverter(w, opts)
+	c.single = true
+	for {
+		if err := c.convertToken(dec); err != nil {
+			return c.finish(err)
+		}
+	}
+}
+
+// ConvertRawMessage converts the JSON value in m to YAML and writes it to w.
+func ConvertRawMessage(w io.Writer, m json.RawMessage, opts ...Option) error {
+	return Convert(w, bytes.NewReader(m), opts...)
 }
 
 type converter struct {
-	w      io.Writer
-	buf    *bytes.Buffer
-	stack  []byte
-	indent int
+	w       io.Writer
+	buf     *bytes.Buffer
+	stack   []byte
+	indent  int
+	written int64 // number of bytes written to w
+	pending int
+	last    byte // last byte flushed to w
+	docs    int  // number of completed documents
+	single  bool // convert only one value
+	read    bool // any token is read from the input
+
+	flushDocs  bool
+	handler    func(Event) error
+	paths      *pathTracker
+	filter     []string
+	splitArray bool
+	wrapArray  bool
+
+	keyTransform   func([]string, string) string
+	valueTransform func([]string, any) (any, bool)
+	heldKey        string
+	holding        bool
+	redactKeys     []string
+	redacting      bool
+	skipDepth      int
+	lazy           bool // do not look ahead as the next token may be skipped
+	expandJSON     bool
+	decompress     bool
+	kubernetesList bool
+	list           *listReader
+	color          bool
+	lineWidth      int
+	blankLines     bool
+	kept           bool // the last value is a keep-chomped block scalar
+	strictNumbers  bool
+	emptyInput     EmptyInput
+	truncate       int
+	column         int // column at the start of buf
+	tags           []pathTag
+	tag            string // tag of the next value
+	tagged         bool   // the opened collection is tagged
+	trailer        string // comment after the next value
+	directives     string
+	header         string
+	schema         *Schema
+	comment        []string // comment lines for the next key
+	validator      *validator
+
+	sourceMap func(int, Position)
+	errorPos  bool
+	tracker   *tracker
+	pos       Position
+	lines     int
+	counted   int
+	mapped    int
+}
+
+// ConvertBytes converts JSON in bs to YAML.
+func ConvertBytes(bs []byte, opts ...Option) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Convert(&buf, bytes.NewReader(bs), opts...); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ConvertString converts JSON in s to YAML.
+func ConvertString(s string, opts ...Option) (string, error) {
+	var sb strings.Builder
+	if err := Convert(&sb, strings.NewReader(s), opts...); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func newConverter(w io.Writer, opts []Option) *converter {
+	c := &converter{w: w, buf: new(bytes.Buffer), stack: []byte{'.'}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *converter) flush() error {
-	_, err := c.w.Write(c.buf.Bytes())
+	if c.sourceMap != nil {
+		c.countLines()
+		c.counted = 0
+	}
+	bs := c.buf.Bytes()
+	if len(bs) > 0 {
+		c.last = bs[len(bs)-1]
+	}
+	if c.lineWidth > 0 {
+		c.column = c.columnAt(len(bs))
+	}
+	n, err := c.w.Write(bs)
+	c.written += int64(n)
 	c.buf.Reset()
-	return err
+	if err != nil {
+		return &WriteError{Written: c.written, Err: err}
+	}
+	return nil
+}
+
+// flushDocument writes the buffered output and flushes the writer if it
+// implements Flush, so that the consumers can see the completed document.
+func (c *converter) flushDocument() error {
+	if err := c.flush(); err != nil {
+		return err
+	}
+	switch w := c.w.(type) {
+	case interface{ Flush() error }:
+		if err := w.Flush(); err != nil {
+			return &WriteError{Written: c.written, Err: err}
+		}
+	case interface{ Flush() }:
+		w.Flush()
+	}
+	return nil
 }
 
 func (c *converter) convert(r io.Reader) error {
 	c.buf.Grow(8 * 1024)
+	dec := c.newDecoder(r)
+	for {
+		if err := c.convertToken(dec); err != nil {
+			return c.finish(err)
+		}
+	}
+}
+
+// endInput handles the end of the input, and returns io.EOF on success.
+func (c *converter) endInput() error {
+	// The input with the empty Kubernetes List objects is not empty.
+	if !c.read && (c.list == nil || !c.list.read) {
+		switch c.emptyInput {
+		case EmptyInputNull:
+			if err := c.writeToken(nil); err != nil {
+				return err
+			}
+		case EmptyInputError:
+			return &ParseError{Err: ErrEmptyInput}
+		}
+	}
+	if c.wrapArray {
+		if err := c.writeToken(json.Delim(']')); err != nil {
+			return err
+		}
+	}
+	return io.EOF
+}
+
+func (c *converter) newDecoder(r io.Reader) *json.Decoder {
+	if c.decompress {
+		r = &decompressReader{r: r}
+	}
+	if c.kubernetesList {
+		c.list = newListReader(r)
+		r = c.list
+	}
+	// The positions in the input rewritten by listReader are not tracked.
+	if (c.sourceMap != nil || c.errorPos) && c.list == nil {
+		c.tracker = &tracker{r: r, pos: Position{Line: 1, Column: 1}}
+		r = c.tracker
+	}
 	dec := json.NewDecoder(r)
 	dec.UseNumber()
-	err := c.convertInternal(dec)
-	if err != nil {
-		if bs := c.buf.Bytes(); len(bs) > 0 && bs[len(bs)-1] != '\n' {
-			c.buf.WriteByte('\n')
-		}
+	return dec
+}
+
+func (c *converter) lastByte() byte {
+	if bs := c.buf.Bytes(); len(bs) > 0 {
+		return bs[len(bs)-1]
+	}
+	return c.last
+}
+
+// finish flushes the output on the error returned by convertToken.
+func (c *converter) finish(err error) error {
+	if err == io.EOF {
+		err = nil
+	} else if b := c.lastByte(); b != 0 && b != '\n' {
+		c.buf.WriteByte('\n')
 	}
 	if ferr := c.flush(); ferr != nil && err == nil {
 		err = ferr
@@ -44,81 +259,218 @@ func (c *converter) convert(r io.Reader) error {
 	return err
 }
 
-func (c *converter) convertInternal(dec *json.Decoder) error {
-	for {
-		token, err := dec.Token()
-		if err != nil {
-			if err == io.EOF {
-				if len(c.stack) == 1 {
-					return nil
-				}
-				err = io.ErrUnexpectedEOF
+// convertToken converts the next token, and returns io.EOF at the end of input.
+func (c *converter) convertToken(dec *json.Decoder) error {
+	if c.wrapArray && len(c.stack) == 1 {
+		// Open the sequence before the first value, which endInput closes.
+		if err := c.writeToken(json.Delim('[')); err != nil {
+			return err
+		}
+	}
+	if c.tracker != nil {
+		c.tracker.commit(dec.InputOffset())
+	}
+	token, err := dec.Token()
+	if err == nil {
+		c.read = true
+		if c.tracker != nil {
+			c.pos = c.tracker.position()
+		}
+	}
+	if err != nil {
+		if err == io.EOF {
+			if c.topLevel() {
+				return c.endInput()
 			}
+			err = io.ErrUnexpectedEOF
+		}
+		var pos Position
+		if c.errorPos && c.tracker != nil {
+			pos = c.tracker.errorPosition(err, dec.InputOffset())
+		}
+		return &ParseError{Pos: pos, Err: err}
+	}
+	if c.strictNumbers {
+		if err := checkNumber(token, dec.Buffered()); err != nil {
+			return &ParseError{Pos: c.pos, Err: err}
+		}
+	}
+	written, err := c.putToken(token)
+	if err != nil {
+		return err
+	}
+	if c.single && c.inputDepth() == 0 {
+		return io.EOF
+	}
+	// Do not look ahead if the next token may be skipped.
+	if !written || c.lazy || (len(c.filter) > 0 || c.splitArray) && c.topLevel() {
+		return nil
+	}
+	// Look ahead the next token to write the indentation or the empty
+	// collection before reading the next token.
+	if dec.More() {
+		c.writeNext()
+	} else {
+		c.writeEnd()
+	}
+	return nil
+}
+
+// topLevel reports whether the next token is at the top level of the input.
+func (c *converter) topLevel() bool {
+	return len(c.stack) == 1 || c.wrapArray && len(c.stack) == 2
+}
+
+const (
+	pendingNone  = iota
+	pendingStart // a collection is opened
+	pendingNext  // a value is written
+)
+
+func (c *converter) writeToken(token json.Token) error {
+	if c.handler != nil {
+		if err := c.handleToken(token); err != nil {
 			return err
 		}
-		if delim, ok := token.(json.Delim); ok {
-			switch delim {
-			case '{', '[':
-				if len(c.stack) > 1 {
-					c.indent += 2
-				}
-				c.stack = append(c.stack, byte(delim))
-				if dec.More() {
-					if c.stack[len(c.stack)-2] == ':' {
-						c.buf.WriteByte('\n')
-						c.writeIndent()
-					}
-					if c.stack[len(c.stack)-1] == '[' {
-						c.buf.WriteString("- ")
-					}
-				} else {
-					if c.stack[len(c.stack)-2] == ':' {
-						c.buf.WriteByte(' ')
-					}
-					if c.stack[len(c.stack)-1] == '{' {
-						c.buf.WriteString("{}\n")
-					} else {
-						c.buf.WriteString("[]\n")
-					}
-				}
-				continue
-			case '}', ']':
-				c.stack = c.stack[:len(c.stack)-1]
-				if len(c.stack) > 1 {
-					c.indent -= 2
+	}
+	if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+		c.writeEnd()
+		c.stack = c.stack[:len(c.stack)-1]
+		if len(c.stack) > 1 {
+			c.indent -= 2
+		}
+	} else {
+		c.writeNext()
+		if ok {
+			if c.tag != "" {
+				if c.stack[len(c.stack)-1] == ':' {
+					c.buf.WriteByte(' ')
 				}
+				c.buf.WriteString(c.tag)
+				c.tag, c.tagged = "", true
 			}
-		} else {
-			switch c.stack[len(c.stack)-1] {
-			case '{':
-				if err := c.writeValue(token); err != nil {
-					return err
-				}
-				c.buf.WriteByte(':')
-				c.stack[len(c.stack)-1] = ':'
-				continue
-			case ':':
+			if len(c.stack) > 1 {
+				c.indent += 2
+			}
+			c.stack = append(c.stack, byte(delim))
+			c.pending = pendingStart
+			return nil
+		}
+		switch c.stack[len(c.stack)-1] {
+		case '{':
+			if err := c.writeValue(token); err != nil {
+				return err
+			}
+			c.buf.WriteByte(':')
+			c.stack[len(c.stack)-1] = ':'
+			return nil
+		case ':':
+			c.buf.WriteByte(' ')
+			fallthrough
+		default:
+			if c.tag != "" {
+				c.buf.WriteString(c.tag)
 				c.buf.WriteByte(' ')
-				fallthrough
-			default:
-				if err := c.writeValue(token); err != nil {
-					return err
-				}
-				c.buf.WriteByte('\n')
+				c.tag = ""
 			}
+			if err := c.writeValue(token); err != nil {
+				return err
+			}
+			c.buf.WriteByte('\n')
 		}
-		if dec.More() {
-			c.writeIndent()
-			switch c.stack[len(c.stack)-1] {
-			case ':':
-				c.stack[len(c.stack)-1] = '{'
-			case '[':
-				c.buf.WriteString("- ")
-			case '.':
-				c.buf.WriteString("---\n")
+	}
+	c.pending = pendingNext
+	if len(c.stack) == 1 {
+		c.docs++
+		if c.handler != nil {
+			if err := c.handler(Event{Kind: EventDocumentEnd}); err != nil {
+				return err
 			}
 		}
+		if c.flushDocs {
+			return c.flushDocument()
+		}
 	}
+	return nil
+}
+
+// writeNext writes the indentation and the indicator for the next value.
+func (c *converter) writeNext() {
+	if len(c.stack) == 1 && (c.directives != "" || c.header != "") {
+		c.writeDocumentStart()
+	}
+	switch c.pending {
+	case pendingStart:
+		if c.tagged || c.stack[len(c.stack)-2] == ':' {
+			c.buf.WriteByte('\n')
+			c.writeIndent()
+		}
+		if c.stack[len(c.stack)-1] == '[' {
+			c.buf.WriteString("- ")
+		}
+	case pendingNext:
+		c.writeBlankLine()
+		c.writeIndent()
+		switch c.stack[len(c.stack)-1] {
+		case ':':
+			c.stack[len(c.stack)-1] = '{'
+		case '[':
+			c.buf.WriteString("- ")
+		case '.':
+			c.writeMarker("---")
+		}
+	}
+	c.pending, c.tagged, c.kept = pendingNone, false, false
+}
+
+// writeBlankLine writes a blank line before the next top-level key. The blank
+// line after the keep-chomped block scalar would be a part of its content.
+func (c *converter) writeBlankLine() {
+	if c.blankLines && len(c.stack) == 2 && c.stack[1] == ':' {
+		if !c.kept {
+			c.buf.WriteByte('\n')
+		}
+		c.stack[1] = '{'
+	}
+}
+
+// writeDocumentStart writes the directives and the header comment at the start
+// of each document.
+func (c *converter) writeDocumentStart() {
+	switch c.pending {
+	case pendingNone:
+		if c.docs > 0 {
+			return
+		}
+	case pendingNext:
+		if c.directives != "" {
+			c.writeMarker("...")
+		}
+	}
+	if c.directives != "" {
+		c.buf.WriteString(c.directives)
+		c.writeMarker("---")
+	} else if c.pending == pendingNext {
+		c.writeMarker("---")
+	}
+	c.buf.WriteString(c.header)
+	c.pending = pendingNone
+}
+
+// writeEnd writes the empty collection if no value is written after opened.
+func (c *converter) writeEnd() {
+	if c.pending == pendingStart {
+		if c.tagged || c.stack[len(c.stack)-2] == ':' {
+			c.buf.WriteByte(' ')
+		}
+		c.mapSource()
+		if c.stack[len(c.stack)-1] == '{' {
+			c.buf.WriteString("{}\n")
+		} else {
+			c.buf.WriteString("[]\n")
+		}
+	}
+	c.pending, c.tagged = pendingNone, false
 }
 
 func (c *converter) writeIndent() {
@@ -139,6 +491,10 @@ func (c *converter) writeIndent() {
 }
 
 func (c *converter) writeValue(v any) error {
+	c.mapSource()
+	if c.color {
+		c.buf.WriteString(c.colorOf(v))
+	}
 	switch v := v.(type) {
 	default:
 		c.buf.WriteString("null")
@@ -150,9 +506,38 @@ func (c *converter) writeValue(v any) error {
 		}
 	case json.Number:
 		c.buf.WriteString(string(v))
+	case float64:
+		// ref: floatEncoder in encoding/json
+		f := byte('f')
+		if a := math.Abs(v); a != 0 && (a < 1e-6 || a >= 1e21) {
+			f = 'e'
+		}
+		b := strconv.AppendFloat(make([]byte, 0, 32), v, f, -1, 64)
+		if f == 'e' {
+			// clean up e-09 to e-9
+			n := len(b)
+			if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+				b[n-2] = b[n-1]
+				b = b[:n-1]
+			}
+		}
+		c.buf.Write(b)
 	case string:
+		if c.truncate > 0 && c.stack[len(c.stack)-1] != '{' {
+			if t, ok := truncateString(v, c.truncate); ok {
+				c.trailer = " # truncated, " + formatSize(len(v))
+				v = t
+			}
+		}
 		c.writeString(v)
 	}
+	if c.color {
+		c.buf.WriteString(colorReset)
+	}
+	if c.trailer != "" {
+		c.buf.WriteString(c.trailer)
+		c.trailer = ""
+	}
 	if c.buf.Len() > 4*1024 {
 		return c.flush()
 	}
@@ -228,7 +613,11 @@ func (c *converter) writeBlockStyleString(v string) {
 		c.buf.WriteByte('-')
 	} else if strings.HasSuffix(v, "\n\n") {
 		c.buf.WriteByte('+')
+		c.kept = true
 	}
+	// The comment is not allowed after the content.
+	c.buf.WriteString(c.trailer)
+	c.trailer = ""
 	c.indent += 2
 	for s := ""; v != ""; {
 		s, v, _ = strings.Cut(v, "\n")
@@ -245,10 +634,99 @@ func (c *converter) writeBlockStyleString(v string) {
 	}
 }
 
-// ref: encodeState#string in encoding/json
+// writeDoubleQuotedString writes the double-quoted string, folding it into
+// multiple lines if it exceeds the line width.
 func (c *converter) writeDoubleQuotedString(s string) {
+	start := c.buf.Len()
+	writeDoubleQuoted(c.buf, s)
+	// The implicit keys cannot span multiple lines.
+	if c.lineWidth <= 0 || c.stack[len(c.stack)-1] == '{' {
+		return
+	}
+	column := c.columnAt(start)
+	if column+c.buf.Len()-start <= c.lineWidth {
+		return
+	}
+	q := append([]byte(nil), c.buf.Bytes()[start:]...)
+	c.buf.Truncate(start)
+	c.indent += 2
+	for offset := 1; column+len(q) > c.lineWidth; offset = 0 {
+		i, space := foldIndex(q, offset, c.lineWidth-column)
+		if i < 0 {
+			break
+		}
+		c.buf.Write(q[:i])
+		if space {
+			// The line break is folded into a space.
+			i++
+		} else {
+			c.buf.WriteByte('\\')
+		}
+		c.buf.WriteByte('\n')
+		c.writeIndent()
+		q, column = q[i:], c.indent
+	}
+	c.indent -= 2
+	c.buf.Write(q)
+}
+
+// foldIndex returns the index to fold the double-quoted string within the
+// width, and whether to fold at the space. The string content starts at the
+// offset; 1 after the opening quote, 0 on the continuation lines. If there is
+// no index within the width, it returns the index of the first space to fold
+// at, or -1.
+func foldIndex(q []byte, offset, width int) (int, bool) {
+	index, space := -1, -1
+	for i := offset + escapeLen(q, offset); i < len(q)-1; i += escapeLen(q, i) {
+		if q[i] == ' ' {
+			if q[i-1] != ' ' && q[i+1] != ' ' && q[i+1] != '"' {
+				if i > width && (space >= 0 || index >= 0) {
+					break
+				}
+				if space = i; i > width {
+					break
+				}
+			}
+		} else if i < width {
+			index = i
+		} else if space >= 0 || index >= 0 {
+			break
+		}
+	}
+	if space >= 0 && (space <= width || index < 0) {
+		return space, true
+	}
+	return index, false
+}
+
+// escapeLen returns the length of the character or the escape sequence at i.
+func escapeLen(q []byte, i int) int {
+	if q[i] == '\\' {
+		switch q[i+1] {
+		case 'x':
+			return 4
+		case 'u':
+			return 6
+		default:
+			return 2
+		}
+	}
+	_, size := utf8.DecodeRune(q[i:])
+	return size
+}
+
+// columnAt returns the column of the index of the buffer.
+func (c *converter) columnAt(i int) int {
+	if j := bytes.LastIndexByte(c.buf.Bytes()[:i], '\n'); j >= 0 {
+		return i - j - 1
+	}
+	return c.column + i
+}
+
+// ref: encodeState#string in encoding/json
+func writeDoubleQuoted(buf *bytes.Buffer, s string) {
 	const hex = "0123456789ABCDEF"
-	c.buf.WriteByte('"')
+	buf.WriteByte('"')
 	start := 0
 	for i := 0; i < len(s); {
 		if b := s[i]; b < utf8.RuneSelf {
@@ -257,25 +735,25 @@ func (c *converter) writeDoubleQuotedString(s string) {
 				continue
 			}
 			if start < i {
-				c.buf.WriteString(s[start:i])
+				buf.WriteString(s[start:i])
 			}
 			switch b {
 			case '"':
-				c.buf.WriteString(`\"`)
+				buf.WriteString(`\"`)
 			case '\\':
-				c.buf.WriteString(`\\`)
+				buf.WriteString(`\\`)
 			case '\b':
-				c.buf.WriteString(`\b`)
+				buf.WriteString(`\b`)
 			case '\f':
-				c.buf.WriteString(`\f`)
+				buf.WriteString(`\f`)
 			case '\n':
-				c.buf.WriteString(`\n`)
+				buf.WriteString(`\n`)
 			case '\r':
-				c.buf.WriteString(`\r`)
+				buf.WriteString(`\r`)
 			case '\t':
-				c.buf.WriteString(`\t`)
+				buf.WriteString(`\t`)
 			default:
-				c.buf.Write([]byte{'\\', 'x', hex[b>>4], hex[b&0xF]})
+				buf.Write([]byte{'\\', 'x', hex[b>>4], hex[b&0xF]})
 			}
 			i++
 			start = i
@@ -285,12 +763,12 @@ func (c *converter) writeDoubleQuotedString(s string) {
 		if r <= '\u009F' || '\uFDD0' <= r && (r == '\uFEFF' ||
 			r <= '\uFDEF' || r == '\uFFFE' || r == '\uFFFF') {
 			if start < i {
-				c.buf.WriteString(s[start:i])
+				buf.WriteString(s[start:i])
 			}
 			if r <= '\u009F' {
-				c.buf.Write([]byte{'\\', 'x', hex[r>>4], hex[r&0xF]})
+				buf.Write([]byte{'\\', 'x', hex[r>>4], hex[r&0xF]})
 			} else {
-				c.buf.Write([]byte{
+				buf.Write([]byte{
 					'\\', 'u', hex[r>>12], hex[r>>8&0xF], hex[r>>4&0xF], hex[r&0xF],
 				})
 			}
@@ -301,7 +779,7 @@ func (c *converter) writeDoubleQuotedString(s string) {
 		i += size
 	}
 	if start < len(s) {
-		c.buf.WriteString(s[start:])
+		buf.WriteString(s[start:])
 	}
-	c.buf.WriteByte('"')
+	buf.WriteByte('"')
 }
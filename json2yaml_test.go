@@ -1,9 +1,12 @@
 package json2yaml_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -333,6 +336,144 @@ w: |-
 	}
 }
 
+func TestConvertBytes(t *testing.T) {
+	got, err := json2yaml.ConvertBytes([]byte(`{"foo":[1,"bar"]}`))
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if want := "foo:\n  - 1\n  - bar\n"; string(got) != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, string(got))
+	}
+	if got, err = json2yaml.ConvertBytes([]byte(`{"foo"`)); err == nil || got != nil {
+		t.Fatalf("should raise an error but got %q, %v", string(got), err)
+	}
+}
+
+func TestConvertString(t *testing.T) {
+	got, err := json2yaml.ConvertString(`{"foo":[1,"bar"]}`)
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if want := "foo:\n  - 1\n  - bar\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if got, err = json2yaml.ConvertString(`{"foo"`); err == nil || got != "" {
+		t.Fatalf("should raise an error but got %q, %v", got, err)
+	}
+}
+
+func TestConvertAll(t *testing.T) {
+	testCases := []struct {
+		name string
+		srcs []string
+		opts []json2yaml.Option
+		want string
+		err  string
+	}{
+		{
+			name: "multiple readers",
+			srcs: []string{`{"foo":128}`, `[1,2] "foo"`, `{}`},
+			want: "foo: 128\n---\n- 1\n- 2\n---\nfoo\n---\n{}\n",
+		},
+		{
+			name: "no trailing white spaces",
+			srcs: []string{`1`, `2`, "3\n"},
+			want: "1\n---\n2\n---\n3\n",
+		},
+		{
+			name: "empty readers",
+			srcs: []string{``, `1`, ` `, `2`, ``},
+			want: "1\n---\n2\n",
+		},
+		{
+			name: "no readers",
+			want: "",
+		},
+		{
+			name: "with header",
+			srcs: []string{`1`, `2`},
+			opts: []json2yaml.Option{json2yaml.WithHeader("DO NOT EDIT.")},
+			want: "# DO NOT EDIT.\n1\n---\n# DO NOT EDIT.\n2\n",
+		},
+		{
+			name: "error",
+			srcs: []string{`1`, `[2,`, `3`},
+			want: "1\n---\n- 2\n- \n",
+			err:  "unexpected EOF",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var rs []io.Reader
+			for _, src := range tc.srcs {
+				rs = append(rs, strings.NewReader(src))
+			}
+			var sb strings.Builder
+			err := json2yaml.ConvertAll(&sb, rs, tc.opts...)
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertDecoder(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(
+		`{"items": [{"foo": [1, 2.50]}, "a\nb", 1e-9, {}], "count": 3}`))
+	for i := 0; i < 3; i++ {
+		if _, err := dec.Token(); err != nil {
+			t.Fatalf("should not raise an error but got: %s", err)
+		}
+	}
+	var sb strings.Builder
+	for {
+		if err := json2yaml.ConvertDecoder(&sb, dec); err != nil {
+			if err != io.EOF {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			break
+		}
+		sb.WriteString("...\n")
+	}
+	if want := "foo:\n  - 1\n  - 2.5\n...\n|-\n  a\n  b\n...\n1e-9\n...\n{}\n...\n"; sb.String() != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, sb.String())
+	}
+	if token, err := dec.Token(); err != nil || token != json.Delim(']') {
+		t.Fatalf("should read the end of array but got: %v, %v", token, err)
+	}
+	if token, err := dec.Token(); err != nil || token != "count" {
+		t.Fatalf("should read the next key but got: %v, %v", token, err)
+	}
+}
+
+func TestConvertRawMessage(t *testing.T) {
+	var v struct {
+		Foo json.RawMessage `json:"foo"`
+	}
+	if err := json.Unmarshal([]byte(`{"foo": {"bar": [1.0, true]}}`), &v); err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	var sb strings.Builder
+	if err := json2yaml.ConvertRawMessage(&sb, v.Foo); err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if want := "bar:\n  - 1.0\n  - true\n"; sb.String() != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, sb.String())
+	}
+}
+
 type errWriter struct{}
 
 func (w errWriter) Write(bs []byte) (int, error) {
@@ -379,6 +520,144 @@ func TestConvertError(t *testing.T) {
 	}
 }
 
+type limitWriter struct {
+	strings.Builder
+	n int
+}
+
+func (w *limitWriter) Write(bs []byte) (int, error) {
+	if w.Len()+len(bs) > w.n {
+		n, _ := w.Builder.Write(bs[:w.n-w.Len()])
+		return n, errors.New("write limit exceeded")
+	}
+	return w.Builder.Write(bs)
+}
+
+func TestConvertWriteError(t *testing.T) {
+	src := `{"foo":"` + strings.Repeat("x", 5000) + `"} {"bar":"` + strings.Repeat("y", 5000) + `"}`
+	w := &limitWriter{n: 8000}
+	err := json2yaml.Convert(w, strings.NewReader(src))
+	var werr *json2yaml.WriteError
+	if !errors.As(err, &werr) {
+		t.Fatalf("should raise *json2yaml.WriteError but got %v", err)
+	}
+	if got, want := werr.Written, int64(w.Len()); got != want {
+		t.Fatalf("should report %d bytes written but got %d", want, got)
+	}
+	if got, want := err.Error(), "write limit exceeded"; got != want {
+		t.Fatalf("should raise an error %q but got error %q", want, got)
+	}
+	var perr *json2yaml.ParseError
+	if errors.As(err, &perr) {
+		t.Fatalf("should not raise *json2yaml.ParseError but got %v", err)
+	}
+}
+
+func TestConvertParseError(t *testing.T) {
+	for _, src := range []string{`{"foo":}`, `[1,2`} {
+		err := json2yaml.Convert(io.Discard, strings.NewReader(src))
+		var perr *json2yaml.ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("should raise *json2yaml.ParseError but got %v", err)
+		}
+		if perr.Pos != (json2yaml.Position{}) {
+			t.Fatalf("should not report the position but got %v", perr.Pos)
+		}
+		if got, want := err.Error(), perr.Err.Error(); got != want {
+			t.Fatalf("should raise an error %q but got error %q", want, got)
+		}
+		var werr *json2yaml.WriteError
+		if errors.As(err, &werr) {
+			t.Fatalf("should not raise *json2yaml.WriteError but got %v", err)
+		}
+	}
+}
+
+type flushWriter struct {
+	strings.Builder
+	flushed []string
+}
+
+func (w *flushWriter) Flush() error {
+	w.flushed = append(w.flushed, w.String())
+	return nil
+}
+
+func TestConvertWithDocumentFlush(t *testing.T) {
+	w := new(flushWriter)
+	err := json2yaml.Convert(w, strings.NewReader(`{"foo":128} [1,2] {} "foo"`),
+		json2yaml.WithDocumentFlush())
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	want := []string{
+		"foo: 128\n",
+		"foo: 128\n---\n- 1\n- 2\n",
+		"foo: 128\n---\n- 1\n- 2\n---\n{}\n",
+		"foo: 128\n---\n- 1\n- 2\n---\n{}\n---\nfoo\n",
+	}
+	if !reflect.DeepEqual(w.flushed, want) {
+		t.Fatalf("should flush\n  %q\nbut got\n  %q", want, w.flushed)
+	}
+}
+
+func TestConvertWithHeader(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		opts []json2yaml.Option
+		want string
+	}{
+		{
+			name: "single line",
+			src:  `{"foo":128}`,
+			opts: []json2yaml.Option{json2yaml.WithHeader("Code generated by json2yaml. DO NOT EDIT.")},
+			want: "# Code generated by json2yaml. DO NOT EDIT.\nfoo: 128\n",
+		},
+		{
+			name: "multiple lines",
+			src:  `[1]`,
+			opts: []json2yaml.Option{json2yaml.WithHeader("Code generated by json2yaml.\n\nsource: foo.json\n")},
+			want: "# Code generated by json2yaml.\n#\n# source: foo.json\n- 1\n",
+		},
+		{
+			name: "multiple documents",
+			src:  `{"foo":128} [] "foo"`,
+			opts: []json2yaml.Option{json2yaml.WithHeader("DO NOT EDIT.")},
+			want: "# DO NOT EDIT.\nfoo: 128\n---\n# DO NOT EDIT.\n[]\n---\n# DO NOT EDIT.\nfoo\n",
+		},
+		{
+			name: "with path",
+			src:  `{"foo":[1]} {"bar":2} {"foo":3}`,
+			opts: []json2yaml.Option{json2yaml.WithHeader("DO NOT EDIT."), json2yaml.WithPath("foo")},
+			want: "# DO NOT EDIT.\n- 1\n---\n# DO NOT EDIT.\n3\n",
+		},
+		{
+			name: "with tag directive",
+			src:  `{"foo":128} {}`,
+			opts: []json2yaml.Option{json2yaml.WithHeader("DO NOT EDIT."), json2yaml.WithTagDirective("!e!", "tag:example.com,2000:")},
+			want: "%TAG !e! tag:example.com,2000:\n---\n# DO NOT EDIT.\nfoo: 128\n...\n%TAG !e! tag:example.com,2000:\n---\n# DO NOT EDIT.\n{}\n",
+		},
+		{
+			name: "empty input",
+			src:  ``,
+			opts: []json2yaml.Option{json2yaml.WithHeader("DO NOT EDIT.")},
+			want: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src), tc.opts...); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
+
 func join(xs []string) string {
 	var sb strings.Builder
 	n := 5*(len(xs)-1) + 1
@@ -429,3 +708,193 @@ func ExampleConvert() {
 	// Output:
 	// Hello: world!
 }
+
+func TestConvertWithWrapArray(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		opts []json2yaml.Option
+		want string
+		err  string
+	}{
+		{
+			name: "multiple values",
+			src:  `{"foo":128} [1,2] {} "foo"`,
+			want: "- foo: 128\n- - 1\n  - 2\n- {}\n- foo\n",
+		},
+		{
+			name: "ndjson",
+			src:  "{\"a\":1,\"b\":[]}\n{\"a\":2,\"b\":[3]}\n",
+			want: "- a: 1\n  b: []\n- a: 2\n  b:\n    - 3\n",
+		},
+		{
+			name: "empty input",
+			src:  ``,
+			want: "[]\n",
+		},
+		{
+			name: "with path",
+			src:  `{"foo":[1]} {"bar":2} {"foo":3}`,
+			opts: []json2yaml.Option{json2yaml.WithPath("foo")},
+			want: "- - 1\n- 3\n",
+		},
+		{
+			name: "with split array",
+			src:  `[1,[2]] [] 3`,
+			opts: []json2yaml.Option{json2yaml.WithSplitArray()},
+			want: "- 1\n- - 2\n- 3\n",
+		},
+		{
+			name: "with header",
+			src:  `1 2`,
+			opts: []json2yaml.Option{json2yaml.WithHeader("DO NOT EDIT.")},
+			want: "# DO NOT EDIT.\n- 1\n- 2\n",
+		},
+		{
+			name: "unexpected EOF",
+			src:  `1 [2`,
+			want: "- 1\n- - 2\n",
+			err:  "unexpected EOF",
+		},
+	}
+	for _, tc := range testCases {
+		for name, convert := range testConverters {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				var sb strings.Builder
+				err := convert(&sb, tc.src, append(tc.opts, json2yaml.WithWrapArray())...)
+				if got, want := sb.String(), tc.want; got != want {
+					t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+				}
+				if tc.err == "" {
+					if err != nil {
+						t.Fatalf("should not raise an error but got: %s", err)
+					}
+				} else {
+					if err == nil {
+						t.Fatalf("should raise an error %q but got no error", tc.err)
+					}
+					if !strings.Contains(err.Error(), tc.err) {
+						t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestConvertWithLineWidth(t *testing.T) {
+	testCases := []struct {
+		name  string
+		src   string
+		width int
+		want  string
+	}{
+		{
+			name:  "fold at spaces",
+			src:   `{"foo":"The quick brown fox jumps over the lazy dog. "}`,
+			width: 20,
+			want:  "foo: \"The quick\n  brown fox jumps\n  over the lazy\n  dog. \"\n",
+		},
+		{
+			name:  "fold with escaped line breaks",
+			src:   `["aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\u0001 "]`,
+			width: 16,
+			want:  "- \"aaaaaaaaaaaa\\\n  aaaaaaaaaaaaa\\\n  aaaaa\\x01 \"\n",
+		},
+		{
+			name:  "leading escape sequence",
+			src:   `[["\"quoted and more words"]]`,
+			width: 7,
+			want:  "- - \"\\\"quoted\n    and\n    mo\\\n    re\n    wo\\\n    rd\\\n    s\"\n",
+		},
+		{
+			name:  "consecutive spaces",
+			src:   `{"foo":{"bar":"aaa  bbb  ccc  ddd "}}`,
+			width: 16,
+			want:  "foo:\n  bar: \"aaa  bb\\\n    b  ccc  dd\\\n    d \"\n",
+		},
+		{
+			name:  "value after long key",
+			src:   `{"long long key":"foo bar baz "}`,
+			width: 10,
+			want:  "long long key: \"foo\n  bar\n  baz \"\n",
+		},
+		{
+			name:  "key",
+			src:   `{"long long key ":"foo "}`,
+			width: 10,
+			want:  "\"long long key \": \"foo \"\n",
+		},
+		{
+			name:  "plain and block styles",
+			src:   `["The quick brown fox jumps over the lazy dog.","The quick brown fox jumps\nover the lazy dog.\n"]`,
+			width: 20,
+			want:  "- The quick brown fox jumps over the lazy dog.\n- |\n  The quick brown fox jumps\n  over the lazy dog.\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithLineWidth(tc.width)); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
+
+func TestConvertWithBlankLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		opts []json2yaml.Option
+		want string
+	}{
+		{
+			name: "top level keys",
+			src:  `{"replicaCount":1,"image":{"repository":"nginx","tag":"1.0"},"ports":[80,443],"env":{}}`,
+			want: "replicaCount: 1\n\nimage:\n  repository: nginx\n  tag: \"1.0\"\n\nports:\n  - 80\n  - 443\n\nenv: {}\n",
+		},
+		{
+			name: "nested mappings",
+			src:  `[{"a":{"b":1,"c":2},"d":3}]`,
+			want: "- a:\n    b: 1\n    c: 2\n  d: 3\n",
+		},
+		{
+			name: "multiple documents",
+			src:  `{"a":1,"b":2} {"c":3,"d":4}`,
+			want: "a: 1\n\nb: 2\n---\nc: 3\n\nd: 4\n",
+		},
+		{
+			name: "with header",
+			src:  `{"a":1,"b":2}`,
+			opts: []json2yaml.Option{json2yaml.WithHeader("Default values.")},
+			want: "# Default values.\na: 1\n\nb: 2\n",
+		},
+		{
+			name: "keep-chomped block scalar",
+			src:  `{"a":"x\n\n","b":{"c":"y\n\n"},"d":"z\n","e":1}`,
+			want: "a: |+\n  x\n\nb:\n  c: |+\n    y\n\nd: |\n  z\n\ne: 1\n",
+		},
+		{
+			name: "with redact keys",
+			src:  `{"a":1,"password":"x","b":2}`,
+			opts: []json2yaml.Option{json2yaml.WithRedactKeys("password")},
+			want: "a: 1\n\npassword: \"***\"\n\nb: 2\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src),
+				append(tc.opts, json2yaml.WithBlankLines())...); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package json2yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// listReader reads the JSON values, replacing the Kubernetes List objects (the
+// objects of kind List, or a kind ending with List, with items) with the items.
+// Each value is read into the memory to find the kind after the items.
+type listReader struct {
+	dec  *json.Decoder
+	buf  bytes.Buffer
+	err  error
+	read bool // any value is read
+}
+
+func newListReader(r io.Reader) *listReader {
+	return &listReader{dec: json.NewDecoder(r)}
+}
+
+func (r *listReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	return r.buf.Read(p)
+}
+
+func (r *listReader) next() error {
+	var v json.RawMessage
+	if err := r.dec.Decode(&v); err != nil {
+		return err
+	}
+	r.read = true
+	var list struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if v[0] == '{' && json.Unmarshal(v, &list) == nil &&
+		strings.HasSuffix(list.Kind, "List") && list.Items != nil {
+		for _, item := range list.Items {
+			r.buf.Write(item)
+			r.buf.WriteByte('\n')
+		}
+		return nil
+	}
+	r.buf.Write(v)
+	r.buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,83 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithKubernetesList(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "list",
+			src: `{"apiVersion":"v1","items":[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}},` +
+				`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"b"}}],"kind":"List","metadata":{"resourceVersion":""}}`,
+			want: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: b\n",
+		},
+		{
+			name: "typed list",
+			src:  `{"kind":"PodList","items":[{"kind":"Pod"}]}`,
+			want: "kind: Pod\n",
+		},
+		{
+			name: "empty list",
+			src:  `{"kind":"List","items":[]} {"kind":"Pod"}`,
+			want: "kind: Pod\n",
+		},
+		{
+			name: "not list",
+			src:  `{"kind":"Pod","items":[1]} {"kind":"List"} [{"kind":"List","items":[]}] "List"`,
+			want: "kind: Pod\nitems:\n  - 1\n---\nkind: List\n---\n- kind: List\n  items: []\n---\nList\n",
+		},
+		{
+			name: "invalid json",
+			src:  `{"kind":"List","items":[{"kind":"Pod"}]} {"kind":"List","items":[1,]}`,
+			want: "kind: Pod\n",
+			err:  "invalid character ']'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithKubernetesList())
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithKubernetesListErrorPosition(t *testing.T) {
+	var sb strings.Builder
+	err := json2yaml.Convert(&sb, strings.NewReader("\n\n{\"x\": tru}"),
+		json2yaml.WithKubernetesList(), json2yaml.WithErrorPosition())
+	var perr *json2yaml.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("should raise a ParseError but got: %v", err)
+	}
+	if perr.Pos != (json2yaml.Position{}) {
+		t.Fatalf("should not report the position but got: %+v", perr.Pos)
+	}
+	if got, want := err.Error(), "invalid character '}' in literal true (expecting 'e')"; got != want {
+		t.Fatalf("should raise an error %q but got error %q", want, got)
+	}
+}
@@ -0,0 +1,68 @@
+package json2yaml
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrInvalidNumber is the error of the number literal not conforming to the
+// grammar of RFC 8259, reported with WithStrictNumbers.
+var ErrInvalidNumber = errors.New("invalid number literal")
+
+// checkNumber checks that the number literal is followed by a delimiter. The
+// decoder accepts the concatenated values at the top level, so 01 and 0x1F
+// are split into 0 and the following values without this check.
+func checkNumber(token json.Token, r io.Reader) error {
+	var s string
+	switch v := token.(type) {
+	case json.Number:
+		s = string(v)
+	case float64:
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return nil
+	}
+	var rest []byte
+	b := make([]byte, 1)
+	for {
+		if n, _ := r.Read(b); n == 0 || isDelimiter(b[0]) {
+			break
+		}
+		if b[0] == '[' || b[0] == '{' || b[0] == '"' {
+			if len(rest) == 0 {
+				return &numberError{fmt.Sprintf("invalid character %q after number %s", b[0], s)}
+			}
+			break
+		}
+		rest = append(rest, b[0])
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidNumber, s+string(rest))
+}
+
+// numberError is ErrInvalidNumber of the number followed by another value.
+type numberError struct {
+	msg string
+}
+
+func (err *numberError) Error() string {
+	return err.msg
+}
+
+func (err *numberError) Is(target error) bool {
+	return target == ErrInvalidNumber
+}
+
+func isDelimiter(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\r', ',', ':', ']', '}':
+		return true
+	default:
+		return false
+	}
+}
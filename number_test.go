@@ -0,0 +1,106 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithStrictNumbers(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "valid numbers",
+			src:  "0 -0 1.50e+3 1E400\n[0,-1.5]\t{\"a\":0}",
+			want: "0\n---\n-0\n---\n1.50e+3\n---\n1E400\n---\n- 0\n- -1.5\n---\na: 0\n",
+		},
+		{
+			name: "leading zero",
+			src:  `01`,
+			want: "",
+			err:  "line 1, column 1: invalid number literal: 01",
+		},
+		{
+			name: "hexadecimal",
+			src:  "[1]\n0x1F",
+			want: "- 1\n---\n",
+			err:  "line 2, column 1: invalid number literal: 0x1F",
+		},
+		{
+			name: "followed by string",
+			src:  `[1] 2"a"`,
+			want: "- 1\n---\n",
+			err:  "line 1, column 5: invalid character '\"' after number 2",
+		},
+		{
+			name: "followed by array",
+			src:  `[1[2]]`,
+			want: "- \n",
+			err:  "line 1, column 2: invalid character '[' after number 1",
+		},
+		{
+			name: "followed by object",
+			src:  `{"a":01{}}`,
+			want: "a:\n",
+			err:  "line 1, column 6: invalid number literal: 01",
+		},
+		{
+			name: "decimal point",
+			src:  `[1.]`,
+			want: "- \n",
+			err:  "line 1, column 4: invalid character ']' after decimal point in numeric literal",
+		},
+		{
+			name: "plus sign",
+			src:  `+5`,
+			want: "",
+			err:  "line 1, column 1: invalid character '+' looking for beginning of value",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src),
+				json2yaml.WithStrictNumbers(), json2yaml.WithErrorPosition())
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if got, want := err.Error(), tc.err; got != want {
+					t.Fatalf("should raise an error %q but got error %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithoutStrictNumbers(t *testing.T) {
+	got, err := json2yaml.ConvertString(`01`)
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if want := "0\n---\n1\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	_, err = json2yaml.ConvertString(`[1] 01`, json2yaml.WithStrictNumbers())
+	if !errors.Is(err, json2yaml.ErrInvalidNumber) {
+		t.Fatalf("should raise json2yaml.ErrInvalidNumber but got %v", err)
+	}
+	_, err = json2yaml.ConvertString(`[1[2]]`, json2yaml.WithStrictNumbers())
+	if !errors.Is(err, json2yaml.ErrInvalidNumber) {
+		t.Fatalf("should raise json2yaml.ErrInvalidNumber but got %v", err)
+	}
+}
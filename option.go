@@ -0,0 +1,252 @@
+package json2yaml
+
+import "strings"
+
+// Option configures the conversion.
+type Option func(*converter)
+
+// WithSourceMap sets a function to be called for each line of the YAML
+// output with the line number (1-based) and the position of the JSON token
+// written at the beginning of the line. This is useful to map diagnostics on
+// the output back to the input.
+func WithSourceMap(f func(line int, pos Position)) Option {
+	return func(c *converter) {
+		c.sourceMap = f
+	}
+}
+
+// WithErrorPosition makes the converter set the position of the invalid
+// character, or the invalid token, to *ParseError on the invalid JSON input.
+func WithErrorPosition() Option {
+	return func(c *converter) {
+		c.errorPos = true
+	}
+}
+
+// WithDecompress makes the converter decompress the input compressed with gzip,
+// which is detected by the magic number. Other input is read as is.
+func WithDecompress() Option {
+	return func(c *converter) {
+		c.decompress = true
+	}
+}
+
+// WithColor makes the converter colorize the object keys, the scalar values,
+// and the document markers with ANSI escape sequences for terminals.
+func WithColor() Option {
+	return func(c *converter) {
+		c.color = true
+	}
+}
+
+// WithLineWidth makes the converter fold the double-quoted strings exceeding
+// the width in bytes into multiple lines. The strings are folded at the spaces
+// if possible, or with the escaped line breaks otherwise. The keys are not
+// folded.
+func WithLineWidth(width int) Option {
+	return func(c *converter) {
+		c.lineWidth = width
+	}
+}
+
+// WithBlankLines makes the converter write a blank line between the top-level
+// keys of the mappings, in the conventional layout of values.yaml files of the
+// Helm charts.
+func WithBlankLines() Option {
+	return func(c *converter) {
+		c.blankLines = true
+	}
+}
+
+// WithTruncate makes the converter truncate the string values longer than n
+// characters, and write the comment with the original size after the values
+// (e.g. "# truncated, 1.2MB"). The keys are not truncated.
+func WithTruncate(n int) Option {
+	return func(c *converter) {
+		c.truncate = n
+	}
+}
+
+// WithStrictNumbers makes the converter reject the number literals which are
+// not delimited from the following values (e.g. 01 and 0x1F), with
+// ErrInvalidNumber. The decoder rejects the other invalid number literals
+// (e.g. 1. and +5), but accepts the concatenated values at the top level, so
+// 01 is converted to the two documents 0 and 1 by default. The number literals
+// are written as they are in both modes (e.g. 1.50e+3 and 1E400).
+func WithStrictNumbers() Option {
+	return func(c *converter) {
+		c.strictNumbers = true
+	}
+}
+
+// WithEmptyInput sets the policy on the input without JSON values, which is
+// empty or only contains white spaces. The default policy is EmptyInputIgnore.
+func WithEmptyInput(policy EmptyInput) Option {
+	return func(c *converter) {
+		c.emptyInput = policy
+	}
+}
+
+// WithDocumentFlush makes the converter write out the output at the end of
+// each document, and call Flush of the writer if it implements Flush() error
+// (e.g. *bufio.Writer) or Flush() (e.g. http.Flusher).
+func WithDocumentFlush() Option {
+	return func(c *converter) {
+		c.flushDocs = true
+	}
+}
+
+// WithEventHandler sets a function to be called for each event of the
+// conversion. If the function returns an error, the conversion stops and
+// the error is returned.
+func WithEventHandler(f func(Event) error) Option {
+	return func(c *converter) {
+		c.handler = f
+	}
+}
+
+// WithPath makes the converter write only the value at the path in each JSON
+// value. The path is a JSON Pointer (e.g. /spec/template) or a path in dot
+// notation (e.g. spec.template), and array elements are addressed by indices.
+// The values without the path are skipped.
+func WithPath(path string) Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.filter = parsePath(path)
+	}
+}
+
+// WithSplitArray makes the converter write each element of the array as a
+// document, when the JSON value (or the value at the path of WithPath) is an
+// array. This is useful to convert paginated results and record dumps.
+func WithSplitArray() Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.splitArray = true
+	}
+}
+
+// WithWrapArray makes the converter write the JSON values in the input (e.g.
+// NDJSON) as the elements of one sequence, instead of multiple documents.
+func WithWrapArray() Option {
+	return func(c *converter) {
+		c.wrapArray = true
+	}
+}
+
+// WithKubernetesList makes the converter write each entry of the items of the
+// Kubernetes List objects (e.g. the output of kubectl get -o json) as a
+// document. The other JSON values are written as they are. The positions in
+// the input are not reported with WithSourceMap and WithErrorPosition, as the
+// input is rewritten before the conversion.
+func WithKubernetesList() Option {
+	return func(c *converter) {
+		c.kubernetesList = true
+	}
+}
+
+// WithKeyTransform sets a function to rewrite the object keys. The function
+// is called with the path to the object in the input and the key, and returns
+// the key to write. The path should not be retained after the call.
+func WithKeyTransform(f func(path []string, key string) string) Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.keyTransform = f
+	}
+}
+
+// WithValueTransform sets a function to rewrite the scalar values. The function
+// is called with the path to the value in the input and the value; nil, bool,
+// json.Number, or string. It returns the value to write, or false to drop the
+// value along with its object key. The path should not be retained after the
+// call.
+func WithValueTransform(f func(path []string, value any) (any, bool)) Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.valueTransform = f
+		c.lazy = true
+	}
+}
+
+// WithRedactKeys makes the converter replace the values of the object keys
+// matching any of the patterns with "***". The patterns are matched against
+// the keys case-insensitively, in the syntax of path.Match (e.g. *password*),
+// except that the wildcards also match the slashes (e.g. vault.io/api-token).
+func WithRedactKeys(patterns ...string) Option {
+	return func(c *converter) {
+		c.trackPaths()
+		for _, pattern := range patterns {
+			c.redactKeys = append(c.redactKeys, strings.ToLower(pattern))
+		}
+		c.lazy = true
+	}
+}
+
+// WithExpandJSON makes the converter expand the string values containing JSON
+// objects or arrays, which are often found in double-encoded log payloads.
+func WithExpandJSON() Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.expandJSON = true
+	}
+}
+
+// WithTag makes the converter write the tag (e.g. !!binary, !Sub) for the
+// values at the paths matching the pattern. The pattern is a JSON Pointer or
+// a path in dot notation, and each element is matched in the syntax of
+// path.Match (e.g. /steps/*/run). The first matching pattern is used when
+// this option is specified multiple times.
+func WithTag(pattern, tag string) Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.tags = append(c.tags, pathTag{parsePath(pattern), tag})
+	}
+}
+
+// WithTagDirective makes the converter write a %TAG directive at the start of
+// each document, to declare the tag handle (e.g. !e!) used in WithTag.
+func WithTagDirective(handle, prefix string) Option {
+	return func(c *converter) {
+		c.directives += "%TAG " + handle + " " + prefix + "\n"
+	}
+}
+
+// WithSchemaComments makes the converter write the descriptions in the schema
+// as comments above the object keys, along with the markers for the required
+// properties.
+func WithSchemaComments(s *Schema) Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.schema = s
+		c.lazy = true
+	}
+}
+
+// WithSchemaValidation makes the converter validate the input against the
+// schema while writing YAML. Each violation is reported as *ValidationError
+// before the invalid value is written, or after the invalid object or array
+// for the violations on the properties and the items. If f is nil, the
+// conversion stops at the first violation. Otherwise f is called with the
+// violation, and the conversion stops if f returns an error.
+func WithSchemaValidation(s *Schema, f func(error) error) Option {
+	return func(c *converter) {
+		c.trackPaths()
+		c.validator = &validator{schema: s, handler: f}
+	}
+}
+
+// WithHeader makes the converter write the header as a comment at the start of
+// each document (e.g. "Code generated by json2yaml. DO NOT EDIT.").
+func WithHeader(header string) Option {
+	return func(c *converter) {
+		var sb strings.Builder
+		for _, line := range strings.Split(strings.TrimRight(header, "\n"), "\n") {
+			if line = strings.TrimRight(line, " \t\r"); line == "" {
+				sb.WriteString("#\n")
+			} else {
+				sb.WriteString("# " + line + "\n")
+			}
+		}
+		c.header += sb.String()
+	}
+}
@@ -0,0 +1,185 @@
+package json2yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathTracker tracks the path to the current token of the input.
+type pathTracker struct {
+	path  []string
+	kinds []byte // '{' before a key, ':' before a value, '[' in an array
+	index []int
+}
+
+// next updates the path for the token, and returns the path of the token and
+// whether the token is an object key. The path of a key is the path of the
+// object, and the path of a delimiter is the path of the collection. The
+// returned path is valid until the next call.
+func (p *pathTracker) next(token json.Token) ([]string, bool) {
+	n := len(p.kinds)
+	if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+		p.path, p.kinds, p.index = p.path[:n-1], p.kinds[:n-1], p.index[:n-1]
+		return p.path, false
+	}
+	if n > 0 {
+		switch p.kinds[n-1] {
+		case '{':
+			p.path[n-1], _ = token.(string)
+			p.kinds[n-1] = ':'
+			return p.path[:n-1], true
+		case ':':
+			p.kinds[n-1] = '{'
+		case '[':
+			p.index[n-1]++
+			p.path[n-1] = strconv.Itoa(p.index[n-1])
+		}
+	}
+	path := p.path
+	if delim, ok := token.(json.Delim); ok {
+		p.path = append(p.path, "")
+		p.kinds = append(p.kinds, byte(delim))
+		p.index = append(p.index, -1)
+	}
+	return path, false
+}
+
+// atKey reports whether the next token is at the position of an object key.
+func (p *pathTracker) atKey() bool {
+	return len(p.kinds) > 0 && p.kinds[len(p.kinds)-1] == '{'
+}
+
+// parsePath parses a JSON Pointer, or a path in dot notation.
+func parsePath(s string) []string {
+	if strings.HasPrefix(s, "/") {
+		path := strings.Split(s[1:], "/")
+		for i, k := range path {
+			path[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(k)
+		}
+		return path
+	}
+	if s = strings.TrimPrefix(s, "."); s == "" {
+		return nil
+	}
+	return strings.Split(s, ".")
+}
+
+func hasPathPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i, k := range prefix {
+		if path[i] != k {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *converter) trackPaths() {
+	if c.paths == nil {
+		c.paths = &pathTracker{}
+	}
+}
+
+// putToken passes the token of the input to writeToken, applying the options
+// depending on the path. It reports whether the token is written.
+func (c *converter) putToken(token json.Token) (bool, error) {
+	if c.paths == nil {
+		return true, c.writeToken(token)
+	}
+	if s, ok := token.(string); ok && c.expandJSON && !c.paths.atKey() && isEmbeddedJSON(s) {
+		return c.putEmbeddedJSON(s)
+	}
+	path, key := c.paths.next(token)
+	if c.validator != nil {
+		if err := c.validator.next(token, path, key); err != nil {
+			return false, err
+		}
+	}
+	if c.skipDepth > 0 {
+		if len(c.paths.kinds) < c.skipDepth {
+			c.skipDepth = 0
+		}
+		return false, nil
+	}
+	if !hasPathPrefix(path, c.filter) {
+		return false, nil
+	}
+	delim, isDelim := token.(json.Delim)
+	if c.splitArray && isDelim && len(path) == len(c.filter) &&
+		(delim == '[' && len(c.paths.kinds) == len(path)+1 || delim == ']' && len(c.paths.kinds) == len(path)) {
+		// Skip the array delimiters to write the elements as the documents.
+		return false, nil
+	}
+	if key {
+		k := token.(string)
+		if c.redactKeys != nil {
+			var err error
+			if c.redacting, err = c.redactKey(k); err != nil {
+				return false, err
+			}
+		}
+		if c.schema != nil {
+			c.comment = c.keyComment(path, k)
+		}
+		if c.keyTransform != nil {
+			token = c.keyTransform(path, k)
+		}
+		if c.valueTransform != nil {
+			// Hold the key until the value is known not to be dropped.
+			c.heldKey, c.holding = token.(string), true
+			return false, nil
+		}
+		return true, c.writeKey(token.(string))
+	}
+	if isDelim && (delim == '}' || delim == ']') {
+		return true, c.writeToken(token)
+	}
+	if c.redacting {
+		c.redacting = false
+		if isDelim {
+			c.skipDepth = len(c.paths.kinds)
+		}
+		token = redacted
+	} else if c.valueTransform != nil && !isDelim {
+		v, ok := c.valueTransform(path, token)
+		if !ok {
+			c.holding, c.comment = false, nil
+			return false, nil
+		}
+		if !validScalar(v) {
+			return false, fmt.Errorf("json2yaml: invalid value %#v returned by value transform", v)
+		}
+		token = v
+	}
+	if c.holding {
+		c.holding = false
+		if err := c.writeKey(c.heldKey); err != nil {
+			return false, err
+		}
+	}
+	if c.tags != nil {
+		c.tag = c.tagFor(path)
+	}
+	return true, c.writeToken(token)
+}
+
+// writeKey writes the object key with the comment.
+func (c *converter) writeKey(key string) error {
+	if c.comment != nil {
+		c.writeComment(c.comment)
+		c.comment = nil
+	}
+	return c.writeToken(key)
+}
+
+// inputDepth returns the number of the enclosing collections of the input.
+func (c *converter) inputDepth() int {
+	if c.paths != nil {
+		return len(c.paths.kinds)
+	}
+	return len(c.stack) - 1
+}
@@ -0,0 +1,278 @@
+package json2yaml_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithPath(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "root",
+			path: "",
+			src:  `{"a":1} [2]`,
+			want: "a: 1\n---\n- 2\n",
+		},
+		{
+			name: "json pointer",
+			path: "/spec/template",
+			src:  `{"kind":"Deployment","spec":{"replicas":1,"template":{"metadata":{"name":"x"},"spec":{}}},"status":{}}`,
+			want: "metadata:\n  name: x\nspec: {}\n",
+		},
+		{
+			name: "dot notation",
+			path: "spec.template.metadata",
+			src:  `{"spec":{"template":{"metadata":{"name":"x"}}}}`,
+			want: "name: x\n",
+		},
+		{
+			name: "array index",
+			path: "/items/1/name",
+			src:  `{"items":[{"name":"a"},{"name":"b"},{"name":"c"}]}`,
+			want: "b\n",
+		},
+		{
+			name: "escaped json pointer",
+			path: "/a~1b/c~0d",
+			src:  `{"a/b":{"c~d":[1,{}]}}`,
+			want: "- 1\n- {}\n",
+		},
+		{
+			name: "empty key",
+			path: "/",
+			src:  `{"":{"x":[]}}`,
+			want: "x: []\n",
+		},
+		{
+			name: "multiple documents",
+			path: "/a",
+			src:  `{"a":[1]} {"b":2} {"a":{"c":3}} [] "a"`,
+			want: "- 1\n---\nc: 3\n",
+		},
+		{
+			name: "not found",
+			path: "/x",
+			src:  `{"a":{"x":1}} {"b":2}`,
+			want: "",
+		},
+		{
+			name: "unexpected EOF",
+			path: "/a/b",
+			src:  `{"a":{"b":[1,2`,
+			want: "- 1\n- 2\n",
+			err:  "unexpected EOF",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithPath(tc.path))
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertDecoderWithPath(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"a":{"b":1},"c":2} {"a":{"b":3}}`))
+	dec.UseNumber()
+	var sb strings.Builder
+	if err := json2yaml.ConvertDecoder(&sb, dec, json2yaml.WithPath("a")); err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if got, want := sb.String(), "b: 1\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if !dec.More() {
+		t.Fatalf("should not consume the next value")
+	}
+}
+
+func TestConvertWithSplitArray(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []json2yaml.Option
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "array",
+			src:  `[1,{"a":[2,3]},[4]]`,
+			want: "1\n---\na:\n  - 2\n  - 3\n---\n- 4\n",
+		},
+		{
+			name: "multiple values",
+			src:  `[] [5] {"x":[1]} 6 [[]]`,
+			want: "5\n---\nx:\n  - 1\n---\n6\n---\n[]\n",
+		},
+		{
+			name: "with path",
+			opts: []json2yaml.Option{json2yaml.WithPath("items")},
+			src:  `{"items":[{"a":1},{"b":[]}],"total":2} {"items":{"c":3}}`,
+			want: "a: 1\n---\nb: []\n---\nc: 3\n",
+		},
+		{
+			name: "unexpected EOF",
+			src:  `[1,[2`,
+			want: "1\n---\n- 2\n",
+			err:  "unexpected EOF",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src),
+				append(tc.opts, json2yaml.WithSplitArray())...)
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithKeyTransform(t *testing.T) {
+	var paths []string
+	f := func(path []string, key string) string {
+		paths = append(paths, strings.Join(append(append([]string{}, path...), key), "/"))
+		var sb strings.Builder
+		for i, r := range key {
+			if 'A' <= r && r <= 'Z' {
+				if i > 0 {
+					sb.WriteByte('_')
+				}
+				r += 'a' - 'A'
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	}
+	var sb strings.Builder
+	err := json2yaml.Convert(&sb, strings.NewReader(
+		`{"apiVersion":"v1","metaData":{"fooBar":[{"bazQux":1},"keyName"]}} {"X":{}}`,
+	), json2yaml.WithKeyTransform(f))
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	want := `api_version: v1
+meta_data:
+  foo_bar:
+    - baz_qux: 1
+    - keyName
+---
+x: {}
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if got, want := strings.Join(paths, " "), "apiVersion metaData metaData/fooBar metaData/fooBar/0/bazQux X"; got != want {
+		t.Fatalf("should be called with\n  %q\nbut got\n  %q", want, got)
+	}
+}
+
+func TestConvertWithValueTransform(t *testing.T) {
+	f := func(path []string, v any) (any, bool) {
+		switch v := v.(type) {
+		case string:
+			if v == "drop" {
+				return nil, false
+			}
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return json.Number(v), true
+			}
+			if strings.Join(path, ".") == "a.invalid" {
+				return []any{}, true
+			}
+		case json.Number:
+			if f, err := v.Float64(); err == nil && strings.Contains(string(v), ".") {
+				return json.Number(fmt.Sprintf("%.1f", f)), true
+			}
+		}
+		return v, true
+	}
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "retype and round",
+			src:  `{"a":"42","b":3.14159,"c":"x","d":[null,"1e3",true]}`,
+			want: "a: 42\nb: 3.1\nc: x\nd:\n  - null\n  - 1e3\n  - true\n",
+		},
+		{
+			name: "drop",
+			src:  `{"a":"drop","b":["drop",1,"drop",{"c":"drop"}],"d":"drop"} "drop" [["drop"]]`,
+			want: "b:\n  - 1\n  - {}\n---\n- []\n",
+		},
+		{
+			name: "large numbers",
+			src:  `{"a":1e400,"b":[-1E-400]}`,
+			want: "a: 1e400\nb:\n  - -1E-400\n",
+		},
+		{
+			name: "invalid value",
+			src:  `{"a":{"b":1,"invalid":"x"}}`,
+			want: "a:\n  b: 1\n",
+			err:  "json2yaml: invalid value []interface {}{} returned by value transform",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithValueTransform(f))
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
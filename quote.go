@@ -0,0 +1,59 @@
+package json2yaml
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// Style is the style of the scalar written by AppendQuoted.
+type Style int
+
+const (
+	// PlainStyle writes the string as is, unless NeedsQuoting reports true,
+	// in which case the string is written in DoubleQuotedStyle.
+	PlainStyle Style = iota
+	// DoubleQuotedStyle writes the string in double quotes with escapes.
+	DoubleQuotedStyle
+	// SingleQuotedStyle writes the string in single quotes, unless the string
+	// contains the characters requiring escapes, in which case the string is
+	// written in DoubleQuotedStyle.
+	SingleQuotedStyle
+)
+
+// NeedsQuoting reports whether the string needs quoting to be written as a
+// plain scalar; the string is ambiguous with other types (e.g. "true" and
+// "1.0"), contains the indicators, or the characters requiring escapes. The
+// rules are the same as the converter quoting the strings.
+func NeedsQuoting(s string) bool {
+	return strings.ContainsRune(s, '\n') || quoteSingleLineStringPattern.MatchString(s)
+}
+
+// AppendQuoted appends the string as a scalar in the style to dst, and returns
+// the extended buffer.
+func AppendQuoted(dst []byte, s string, style Style) []byte {
+	switch style {
+	case PlainStyle:
+		if !NeedsQuoting(s) {
+			return append(dst, s...)
+		}
+	case SingleQuotedStyle:
+		if !escapeStringPattern.MatchString(s) {
+			dst = append(dst, '\'')
+			dst = append(dst, strings.ReplaceAll(s, "'", "''")...)
+			return append(dst, '\'')
+		}
+	}
+	buf := bytes.NewBuffer(dst)
+	writeDoubleQuoted(buf, s)
+	return buf.Bytes()
+}
+
+// escapeStringPattern matches the characters escaped in double quotes, except
+// for '\\' and '"'.
+var escapeStringPattern = regexp.MustCompile(
+	// C0 control codes - '\t', DEL
+	"[\u0000-\u0008\u000A-\u001F\u007F" +
+		// C1 control codes, BOM, noncharacters
+		"\u0080-\u009F\uFEFF\uFDD0-\uFDEF\uFFFE\uFFFF]",
+)
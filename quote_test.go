@@ -0,0 +1,58 @@
+package json2yaml_test
+
+import (
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestNeedsQuoting(t *testing.T) {
+	testCases := []struct {
+		src  string
+		want bool
+	}{
+		{"foo", false},
+		{"foo bar", false},
+		{"", true},
+		{"true", true},
+		{"No", true},
+		{"1.0", true},
+		{"0x1F", true},
+		{"2022-08-04", true},
+		{"- foo", true},
+		{"foo: bar", true},
+		{"foo #bar", true},
+		{" foo", true},
+		{"a\nb", true},
+		{" ", false},
+		{"\u0085", true},
+	}
+	for _, tc := range testCases {
+		if got := json2yaml.NeedsQuoting(tc.src); got != tc.want {
+			t.Errorf("NeedsQuoting(%q) should be %t but got %t", tc.src, tc.want, got)
+		}
+	}
+}
+
+func TestAppendQuoted(t *testing.T) {
+	testCases := []struct {
+		src   string
+		style json2yaml.Style
+		want  string
+	}{
+		{"foo", json2yaml.PlainStyle, "foo"},
+		{"true", json2yaml.PlainStyle, `"true"`},
+		{"a\nb\t\"c\"\\", json2yaml.PlainStyle, `"a\nb\t\"c\"\\"`},
+		{"foo", json2yaml.DoubleQuotedStyle, `"foo"`},
+		{"\x00\x7F\u0085\uFEFF", json2yaml.DoubleQuotedStyle, `"\x00\x7F\x85\uFEFF"`},
+		{"foo", json2yaml.SingleQuotedStyle, `'foo'`},
+		{"it's \"a\"\t\\", json2yaml.SingleQuotedStyle, `'it''s "a"	\'`},
+		{"", json2yaml.SingleQuotedStyle, `''`},
+		{"a\nb", json2yaml.SingleQuotedStyle, `"a\nb"`},
+	}
+	for _, tc := range testCases {
+		if got := string(json2yaml.AppendQuoted([]byte("x: "), tc.src, tc.style)); got != "x: "+tc.want {
+			t.Errorf("AppendQuoted(%q, %d) should be %q but got %q", tc.src, tc.style, "x: "+tc.want, got)
+		}
+	}
+}
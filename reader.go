@@ -0,0 +1,42 @@
+package json2yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// NewReader returns a reader which reads JSON from r and produces YAML on
+// demand. The conversion proceeds only as the returned reader is read, so it
+// can be used in pull-based pipelines without a goroutine.
+func NewReader(r io.Reader, opts ...Option) io.Reader {
+	cr := &reader{}
+	cr.c = newConverter(&cr.out, opts)
+	cr.dec = cr.c.newDecoder(r)
+	return cr
+}
+
+type reader struct {
+	c   *converter
+	dec *json.Decoder
+	out bytes.Buffer
+	err error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if err := r.c.convertToken(r.dec); err != nil {
+			if r.err = r.c.finish(err); r.err == nil {
+				r.err = io.EOF
+			}
+		} else if r.c.buf.Len() > 0 {
+			if err := r.c.flush(); err != nil {
+				r.err = err
+			}
+		}
+	}
+	return r.out.Read(p)
+}
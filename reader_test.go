@@ -0,0 +1,90 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestNewReader(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "nested object and array",
+			src:  `{"foo":[0,{"bar":[],"foo":{}},[{"foo":[{"foo":[]}]}],[[[{}]]]],"bar":[{}]}`,
+			want: `foo:
+  - 0
+  - bar: []
+    foo: {}
+  - - foo:
+        - foo: []
+  - - - - {}
+bar:
+  - {}
+`,
+		},
+		{
+			name: "multiple values",
+			src:  `{"x": "a\nb\n"} [1, 2] "foo"`,
+			want: "x: |\n  a\n  b\n---\n- 1\n- 2\n---\nfoo\n",
+		},
+		{
+			name: "large array",
+			src:  "[" + strings.Repeat(`"test",`, 999) + `"test"]`,
+			want: strings.Repeat("- test\n", 1000),
+		},
+		{
+			name: "unexpected character in array",
+			src:  "[1,%",
+			want: "- 1\n- \n",
+			err:  "invalid character '%'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := json2yaml.NewReader(strings.NewReader(tc.src))
+			got, err := io.ReadAll(iotest.OneByteReader(r))
+			if string(got) != tc.want {
+				t.Fatalf("should read\n  %q\nbut got\n  %q", tc.want, string(got))
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewReaderLazy(t *testing.T) {
+	r := json2yaml.NewReader(io.MultiReader(
+		strings.NewReader(`{"foo":128}`),
+		iotest.ErrReader(errors.New("read error")),
+	))
+	bs := make([]byte, len("foo: 128\n"))
+	n, err := io.ReadFull(r, bs)
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if got, want := string(bs[:n]), "foo: 128\n"; got != want {
+		t.Fatalf("should read %q but got %q", want, got)
+	}
+	if _, err = r.Read(bs); err == nil || err.Error() != "read error" {
+		t.Fatalf("should raise an error %q but got %v", "read error", err)
+	}
+}
@@ -0,0 +1,83 @@
+package json2yaml
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+const redacted = "***"
+
+// redactKey reports whether the value of the key should be redacted.
+func (c *converter) redactKey(key string) (bool, error) {
+	key = strings.ToLower(key)
+	for _, pattern := range c.redactKeys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return false, fmt.Errorf("json2yaml: %w: %q", err, pattern)
+		}
+		if matchKey(pattern, key) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchKey reports whether the key matches the pattern in the syntax of
+// path.Match, except that the wildcards also match the slashes in the keys
+// like vault.io/api-token. The pattern is validated by path.Match beforehand.
+func matchKey(pattern, key string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			for i := range key {
+				if matchKey(pattern, key[i:]) {
+					return true
+				}
+			}
+			return pattern == ""
+		case '?':
+			if key == "" {
+				return false
+			}
+			_, n := utf8.DecodeRuneInString(key)
+			pattern, key = pattern[1:], key[n:]
+		case '[':
+			if key == "" {
+				return false
+			}
+			i := classEnd(pattern)
+			_, n := utf8.DecodeRuneInString(key)
+			if matched, _ := path.Match(pattern[:i], key[:n]); !matched {
+				return false
+			}
+			pattern, key = pattern[i:], key[n:]
+		default:
+			if pattern[0] == '\\' {
+				pattern = pattern[1:]
+			}
+			_, n := utf8.DecodeRuneInString(pattern)
+			if !strings.HasPrefix(key, pattern[:n]) {
+				return false
+			}
+			pattern, key = pattern[n:], key[n:]
+		}
+	}
+	return key == ""
+}
+
+// classEnd returns the index after the character class at the start of the
+// pattern.
+func classEnd(pattern string) int {
+	i := 1
+	if pattern[i] == '^' {
+		i++
+	}
+	for ; pattern[i] != ']'; i++ {
+		if pattern[i] == '\\' {
+			i++
+		}
+	}
+	return i + 1
+}
@@ -0,0 +1,77 @@
+package json2yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithRedactKeys(t *testing.T) {
+	testCases := []struct {
+		name     string
+		patterns []string
+		src      string
+		want     string
+		err      string
+	}{
+		{
+			name:     "scalars",
+			patterns: []string{"*password*", "*token*"},
+			src:      `{"user":"foo","password":"bar","DB_PASSWORD":1,"tokens":null,"x":{"accessToken":true}}`,
+			want:     "user: foo\npassword: \"***\"\nDB_PASSWORD: \"***\"\ntokens: \"***\"\nx:\n  accessToken: \"***\"\n",
+		},
+		{
+			name:     "slashes",
+			patterns: []string{"*password*", "*token*", "example.com/*"},
+			src:      `{"vault.io/api-token":"foo","x/password":"bar","example.com/a/b":1,"example.org/c":2}`,
+			want:     "vault.io/api-token: \"***\"\nx/password: \"***\"\nexample.com/a/b: \"***\"\nexample.org/c: 2\n",
+		},
+		{
+			name:     "wildcards and classes",
+			patterns: []string{"a?b", "[xy]/[^a-z]", `\*`},
+			src:      `{"a/b":1,"a//b":2,"x/1":3,"y/z":4,"*":5,"**":6}`,
+			want:     "a/b: \"***\"\na//b: 2\nx/1: \"***\"\ny/z: 4\n\"*\": \"***\"\n\"**\": 6\n",
+		},
+		{
+			name:     "collections",
+			patterns: []string{"secret?"},
+			src:      `{"secrets":{"a":[1,{"b":2}],"c":3},"secret":[],"other":[{"secret1":[[]]},"secret2"]}`,
+			want:     "secrets: \"***\"\nsecret: []\nother:\n  - secret1: \"***\"\n  - secret2\n",
+		},
+		{
+			name:     "multiple documents",
+			patterns: []string{"key"},
+			src:      `{"key":{"x":1}} {"key":[2]} ["key"]`,
+			want:     "key: \"***\"\n---\nkey: \"***\"\n---\n- key\n",
+		},
+		{
+			name:     "invalid pattern",
+			patterns: []string{"[a"},
+			src:      `{"x":{"y":1}}`,
+			want:     "",
+			err:      `json2yaml: syntax error in pattern: "[a"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithRedactKeys(tc.patterns...))
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
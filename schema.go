@@ -0,0 +1,255 @@
+package json2yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Schema is a JSON Schema. The keywords not used by the converter are ignored.
+// A Schema parsed by ParseSchema has the local references ($ref) resolved.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 []string           `json:"-"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"-"`
+	ExclusiveMaximum     *float64           `json:"-"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+
+	ref     *Schema // resolved $ref
+	never   bool    // false schema
+	pattern *regexp.Regexp
+}
+
+// ParseSchema reads a JSON Schema from r.
+func ParseSchema(r io.Reader) (*Schema, error) {
+	var s Schema
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("json2yaml: invalid schema: %w", err)
+	}
+	if err := s.resolveRefs(&s, make(map[*Schema]bool)); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting boolean schemas.
+func (s *Schema) UnmarshalJSON(bs []byte) error {
+	switch string(bs) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+	type schema Schema
+	var aux struct {
+		*schema
+		Type             json.RawMessage `json:"type"`
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum"`
+	}
+	aux.schema = (*schema)(s)
+	if err := json.Unmarshal(bs, &aux); err != nil {
+		return err
+	}
+	if len(aux.Type) > 0 {
+		if err := json.Unmarshal(aux.Type, &s.Type); err != nil {
+			var t string
+			if err := json.Unmarshal(aux.Type, &t); err != nil {
+				return err
+			}
+			s.Type = []string{t}
+		}
+	}
+	for _, x := range []struct {
+		raw       json.RawMessage
+		exclusive **float64
+		bound     **float64
+	}{
+		{aux.ExclusiveMinimum, &s.ExclusiveMinimum, &s.Minimum},
+		{aux.ExclusiveMaximum, &s.ExclusiveMaximum, &s.Maximum},
+	} {
+		switch string(x.raw) {
+		case "", "false":
+		case "true": // draft 4
+			*x.exclusive, *x.bound = *x.bound, nil
+		default:
+			if err := json.Unmarshal(x.raw, x.exclusive); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) resolveRefs(root *Schema, seen map[*Schema]bool) error {
+	if s == nil || seen[s] {
+		return nil
+	}
+	seen[s] = true
+	if s.Ref != "" {
+		if s.ref = root.lookup(s.Ref); s.ref == nil {
+			return fmt.Errorf("json2yaml: cannot resolve schema reference %q", s.Ref)
+		}
+	}
+	if s.Pattern != "" {
+		if _, err := s.compilePattern(); err != nil {
+			return err
+		}
+	}
+	for _, m := range []map[string]*Schema{s.Properties, s.Defs, s.Definitions} {
+		for _, t := range m {
+			if err := t.resolveRefs(root, seen); err != nil {
+				return err
+			}
+		}
+	}
+	for _, t := range append([]*Schema{s.AdditionalProperties, s.Items}, s.AllOf...) {
+		if err := t.resolveRefs(root, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the schema at the local reference (e.g. #/$defs/foo).
+func (s *Schema) lookup(ref string) *Schema {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	path := parsePath(ref[1:])
+	for i := 0; s != nil && i < len(path); i++ {
+		switch path[i] {
+		case "properties", "$defs", "definitions":
+			if i++; i == len(path) {
+				return nil
+			}
+			s = map[string]map[string]*Schema{
+				"properties": s.Properties, "$defs": s.Defs, "definitions": s.Definitions,
+			}[path[i-1]][path[i]]
+		case "additionalProperties":
+			s = s.AdditionalProperties
+		case "items":
+			s = s.Items
+		default:
+			return nil
+		}
+	}
+	return s
+}
+
+// resolve follows the references.
+func (s *Schema) resolve() *Schema {
+	for i := 0; s != nil && s.ref != nil && i < 32; i++ {
+		s = s.ref
+	}
+	return s
+}
+
+// property returns the schema of the object property.
+func (s *Schema) property(key string) *Schema {
+	if s = s.resolve(); s == nil {
+		return nil
+	}
+	if t, ok := s.Properties[key]; ok {
+		return t.resolve()
+	}
+	return s.AdditionalProperties.resolve()
+}
+
+// required reports whether the object property is required.
+func (s *Schema) required(key string) bool {
+	if s = s.resolve(); s != nil {
+		for _, k := range s.Required {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schemaAt returns the schema of the value at the path of the input.
+func (c *converter) schemaAt(path []string) *Schema {
+	s := c.schema.resolve()
+	for i := 0; s != nil && i < len(path); i++ {
+		if c.paths.kinds[i] == '[' {
+			s = s.Items.resolve()
+		} else {
+			s = s.property(path[i])
+		}
+	}
+	return s
+}
+
+// keyComment returns the comment lines for the object key.
+func (c *converter) keyComment(path []string, key string) []string {
+	s := c.schemaAt(path)
+	var lines []string
+	if t := s.property(key); t != nil && t.Description != "" {
+		for _, line := range strings.Split(strings.TrimRight(t.Description, "\n"), "\n") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	if s.required(key) {
+		lines = append(lines, "required")
+	}
+	return lines
+}
+
+// writeComment writes the comment lines before the next object key.
+func (c *converter) writeComment(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	if c.pending == pendingStart {
+		if c.tagged || c.stack[len(c.stack)-2] == ':' {
+			c.buf.WriteByte('\n')
+		} else if c.stack[len(c.stack)-2] == '[' {
+			// write the first line after the sequence entry indicator
+			c.writeCommentLine(lines[0])
+			lines = lines[1:]
+		}
+		c.tagged = false
+	} else if c.pending == pendingNext {
+		// write the blank line above the comment
+		c.writeBlankLine()
+	}
+	for _, line := range lines {
+		c.writeIndent()
+		c.writeCommentLine(line)
+	}
+	c.pending = pendingNext
+}
+
+func (c *converter) writeCommentLine(line string) {
+	if line == "" {
+		c.buf.WriteString("#\n")
+	} else {
+		c.buf.WriteString("# ")
+		c.buf.WriteString(line)
+		c.buf.WriteByte('\n')
+	}
+}
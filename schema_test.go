@@ -0,0 +1,146 @@
+package json2yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+const testSchema = `{
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string", "description": "The name of the application."},
+    "replicas": {"type": "integer", "description": "The number of replicas.\n\nDefaults to 1."},
+    "containers": {"type": "array", "items": {"$ref": "#/$defs/container"}},
+    "labels": {"type": "object", "additionalProperties": {"description": "A label value."}},
+    "spec": {"$ref": "#/definitions/spec"}
+  },
+  "$defs": {
+    "container": {
+      "type": "object",
+      "required": ["image"],
+      "properties": {
+        "image": {"description": "The container image."},
+        "args": {"description": "The arguments."}
+      }
+    }
+  },
+  "definitions": {
+    "spec": {"properties": {"enabled": {"description": "Enables the feature."}}}
+  }
+}`
+
+func TestConvertWithSchemaComments(t *testing.T) {
+	schema, err := json2yaml.ParseSchema(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "top level",
+			src:  `{"name":"app","replicas":2,"unknown":true}`,
+			want: `# The name of the application.
+# required
+name: app
+# The number of replicas.
+#
+# Defaults to 1.
+replicas: 2
+unknown: true
+`,
+		},
+		{
+			name: "nested",
+			src:  `{"containers":[{"image":"foo","args":[]},{}],"labels":{"a":"b"},"spec":{"enabled":true}}`,
+			want: `containers:
+  - # The container image.
+    # required
+    image: foo
+    # The arguments.
+    args: []
+  - {}
+labels:
+  # A label value.
+  a: b
+spec:
+  # Enables the feature.
+  enabled: true
+`,
+		},
+		{
+			name: "multiple documents",
+			src:  `{"name":"a"} {"name":"b"} []`,
+			want: `# The name of the application.
+# required
+name: a
+---
+# The name of the application.
+# required
+name: b
+---
+[]
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithSchemaComments(schema)); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
+
+func TestParseSchemaError(t *testing.T) {
+	testCases := []struct {
+		src string
+		err string
+	}{
+		{`{"properties":`, "json2yaml: invalid schema: unexpected EOF"},
+		{`{"properties":{"a":{"$ref":"#/$defs/x"}}}`, `json2yaml: cannot resolve schema reference "#/$defs/x"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			_, err := json2yaml.ParseSchema(strings.NewReader(tc.src))
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("should raise an error %q but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestConvertWithSchemaCommentsBlankLines(t *testing.T) {
+	schema, err := json2yaml.ParseSchema(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	var sb strings.Builder
+	if err := json2yaml.Convert(&sb, strings.NewReader(`{"name":"app","replicas":2,"unknown":true}`),
+		json2yaml.WithSchemaComments(schema), json2yaml.WithBlankLines()); err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	want := `# The name of the application.
+# required
+name: app
+
+# The number of replicas.
+#
+# Defaults to 1.
+replicas: 2
+
+unknown: true
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+}
@@ -0,0 +1,121 @@
+package json2yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Position represents a position in the JSON input.
+type Position struct {
+	Offset int64 // byte offset, starting at 0
+	Line   int   // line number, starting at 1
+	Column int   // column number in bytes, starting at 1
+}
+
+// ParseError is an error of the JSON input, including the errors reading the
+// input. The position of the token is set with WithErrorPosition.
+type ParseError struct {
+	Pos Position // zero without WithErrorPosition
+	Err error
+}
+
+func (err *ParseError) Error() string {
+	if err.Pos.Line == 0 {
+		return err.Err.Error()
+	}
+	return fmt.Sprintf("line %d, column %d: %s", err.Pos.Line, err.Pos.Column, err.Err)
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// tracker records the bytes read by the decoder which are not yet committed,
+// in order to calculate the positions of the tokens.
+type tracker struct {
+	r   io.Reader
+	buf []byte
+	idx int      // index of the first uncommitted byte in buf
+	pos Position // position of buf[idx]
+}
+
+func (t *tracker) Read(p []byte) (int, error) {
+	if t.idx > len(t.buf)/2 {
+		t.buf, t.idx = t.buf[:copy(t.buf, t.buf[t.idx:])], 0
+	}
+	n, err := t.r.Read(p)
+	t.buf = append(t.buf, p[:n]...)
+	return n, err
+}
+
+// commit discards the bytes before the offset.
+func (t *tracker) commit(offset int64) {
+	if n := int(offset - t.pos.Offset); n > 0 {
+		t.advance(n)
+	}
+}
+
+func (t *tracker) advance(n int) {
+	bs := t.buf[t.idx : t.idx+n]
+	if i := bytes.LastIndexByte(bs, '\n'); i >= 0 {
+		t.pos.Line += bytes.Count(bs, []byte{'\n'})
+		t.pos.Column = n - i
+	} else {
+		t.pos.Column += n
+	}
+	t.pos.Offset += int64(n)
+	t.idx += n
+}
+
+// position returns the position of the token next to the committed offset.
+func (t *tracker) position() Position {
+	i := t.idx
+	for ; i < len(t.buf); i++ {
+		switch t.buf[i] {
+		case ' ', '\t', '\n', '\r', ',', ':':
+			continue
+		}
+		break
+	}
+	t.advance(i - t.idx)
+	return t.pos
+}
+
+// errorPosition returns the position of the error returned by the decoder at
+// the offset; the invalid character of the syntax error, or the end of the
+// input read. The offsets of the syntax errors returned by json.Decoder.Token
+// are inconsistent, so the value at the offset is decoded again to locate it.
+func (t *tracker) errorPosition(err error, offset int64) Position {
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		t.commit(offset)
+		var m json.RawMessage
+		dec := json.NewDecoder(bytes.NewReader(t.buf[t.idx:]))
+		if errors.As(dec.Decode(&m), &serr) {
+			t.commit(t.pos.Offset + serr.Offset - 1)
+		}
+	} else {
+		t.commit(t.pos.Offset + int64(len(t.buf)-t.idx))
+	}
+	return t.pos
+}
+
+func (c *converter) countLines() {
+	bs := c.buf.Bytes()
+	c.lines += bytes.Count(bs[c.counted:], []byte{'\n'})
+	c.counted = len(bs)
+}
+
+func (c *converter) mapSource() {
+	if c.sourceMap == nil || c.tracker == nil {
+		return
+	}
+	c.countLines()
+	if line := c.lines + 1; line > c.mapped {
+		c.mapped = line
+		c.sourceMap(line, c.pos)
+	}
+}
@@ -0,0 +1,144 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithSourceMap(t *testing.T) {
+	type mapping struct {
+		line int
+		pos  json2yaml.Position
+	}
+	testCases := []struct {
+		name string
+		src  string
+		want []mapping
+	}{
+		{
+			name: "scalars",
+			src:  "null 128\n\"foo\"",
+			want: []mapping{
+				{1, json2yaml.Position{Offset: 0, Line: 1, Column: 1}},
+				{3, json2yaml.Position{Offset: 5, Line: 1, Column: 6}},
+				{5, json2yaml.Position{Offset: 9, Line: 2, Column: 1}},
+			},
+		},
+		{
+			name: "nested object and array",
+			src: `{
+  "foo": [1, {"bar": "a\nb"}],
+  "baz": {}
+}
+[[1,
+  2]]`,
+			want: []mapping{
+				{1, json2yaml.Position{Offset: 4, Line: 2, Column: 3}},
+				{2, json2yaml.Position{Offset: 12, Line: 2, Column: 11}},
+				{3, json2yaml.Position{Offset: 16, Line: 2, Column: 15}},
+				{6, json2yaml.Position{Offset: 35, Line: 3, Column: 3}},
+				{8, json2yaml.Position{Offset: 49, Line: 5, Column: 3}},
+				{9, json2yaml.Position{Offset: 54, Line: 6, Column: 3}},
+			},
+		},
+		{
+			name: "large array",
+			src:  "[" + strings.Repeat(`"test",`, 999) + `"test"]`,
+			want: (func() []mapping {
+				ms := make([]mapping, 1000)
+				for i := range ms {
+					ms[i] = mapping{i + 1, json2yaml.Position{
+						Offset: int64(1 + i*7), Line: 1, Column: 2 + i*7,
+					}}
+				}
+				return ms
+			})(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			var got []mapping
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src),
+				json2yaml.WithSourceMap(func(line int, pos json2yaml.Position) {
+					got = append(got, mapping{line, pos})
+				})); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("should map\n  %v\nbut got\n  %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestConvertWithErrorPosition(t *testing.T) {
+	testCases := []struct {
+		src string
+		pos json2yaml.Position
+		err string
+	}{
+		{
+			src: "{\n  \"foo\": [1,\n  x]\n}",
+			pos: json2yaml.Position{Offset: 17, Line: 3, Column: 3},
+			err: "line 3, column 3: invalid character 'x' looking for beginning of value",
+		},
+		{
+			src: `[1, 2, 3 4]`,
+			pos: json2yaml.Position{Offset: 9, Line: 1, Column: 10},
+			err: "line 1, column 10: invalid character '4' after array element",
+		},
+		{
+			src: "[1,2] [3,4]\n{\"foo\": tru}",
+			pos: json2yaml.Position{Offset: 23, Line: 2, Column: 12},
+			err: "line 2, column 12: invalid character '}' in literal true (expecting 'e')",
+		},
+		{
+			src: `{"foo":"bar\x"}`,
+			pos: json2yaml.Position{Offset: 12, Line: 1, Column: 13},
+			err: "line 1, column 13: invalid character 'x' in string escape code",
+		},
+		{
+			src: `[1,,2]`,
+			pos: json2yaml.Position{Offset: 3, Line: 1, Column: 4},
+			err: "line 1, column 4: invalid character ',' looking for beginning of value",
+		},
+		{
+			src: `{"foo"::1}`,
+			pos: json2yaml.Position{Offset: 7, Line: 1, Column: 8},
+			err: "line 1, column 8: invalid character ':' looking for beginning of value",
+		},
+		{
+			src: "{\"foo\":\n",
+			pos: json2yaml.Position{Offset: 8, Line: 2, Column: 1},
+			err: "line 2, column 1: unexpected EOF",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithErrorPosition())
+			var perr *json2yaml.ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("should raise *json2yaml.ParseError but got %v", err)
+			}
+			if perr.Pos != tc.pos {
+				t.Fatalf("should report position %v but got %v", tc.pos, perr.Pos)
+			}
+			if got, want := err.Error(), tc.err; got != want {
+				t.Fatalf("should raise an error %q but got error %q", want, got)
+			}
+			err = json2yaml.Validate(strings.NewReader(tc.src))
+			if !errors.As(err, &perr) {
+				t.Fatalf("should raise *json2yaml.ParseError but got %v", err)
+			}
+			if perr.Pos != tc.pos {
+				t.Fatalf("should report position %v on validation but got %v", tc.pos, perr.Pos)
+			}
+		})
+	}
+}
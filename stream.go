@@ -0,0 +1,124 @@
+package json2yaml
+
+import "io"
+
+// Stream is an incremental converter for JSON delivered in bursts. Call Push
+// with each chunk of JSON, Flush to write out the converted YAML, and Close at
+// the end of the input. A Stream must be closed to release its resources.
+type Stream struct {
+	c       *converter
+	r       *chanReader
+	waiting bool
+	closed  bool
+	done    chan struct{}
+	err     error
+}
+
+// NewStream returns a new Stream writing YAML to w.
+func NewStream(w io.Writer, opts ...Option) *Stream {
+	s := &Stream{
+		c:    newConverter(w, opts),
+		r:    &chanReader{in: make(chan []byte), idle: make(chan struct{})},
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		dec := s.c.newDecoder(s.r)
+		for {
+			if err := s.c.convertToken(dec); err != nil {
+				s.err = s.c.finish(err)
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// Push converts as much of the JSON as possible, including the input buffered
+// by the previous calls. The converted YAML is buffered until Flush is called,
+// or written when the buffer grows large.
+func (s *Stream) Push(p []byte) error {
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+	if err := s.wait(); err != nil {
+		return err
+	}
+	if len(p) == 0 {
+		return nil
+	}
+	s.r.in <- p
+	s.waiting = false
+	return s.wait()
+}
+
+// wait blocks until the converter consumes all the input.
+func (s *Stream) wait() error {
+	if !s.waiting {
+		select {
+		case <-s.r.idle:
+			s.waiting = true
+		case <-s.done:
+			return s.err
+		}
+	}
+	return nil
+}
+
+// Documents returns the number of documents converted so far. Comparing this
+// value before and after Push tells whether a document boundary was reached.
+func (s *Stream) Documents() int {
+	_ = s.wait()
+	return s.c.docs
+}
+
+// Flush writes the converted YAML to the underlying writer, and flushes the
+// writer if it implements Flush.
+func (s *Stream) Flush() error {
+	if s.closed {
+		return s.err
+	}
+	if err := s.wait(); err != nil {
+		return err
+	}
+	return s.c.flushDocument()
+}
+
+// Close finalizes the conversion and writes the remaining YAML. It returns an
+// error if the input ends in the middle of a value.
+func (s *Stream) Close() error {
+	if !s.closed {
+		s.closed = true
+		if s.wait() == nil {
+			close(s.r.in)
+		}
+		<-s.done
+	}
+	return s.err
+}
+
+// chanReader is a reader receiving chunks from a channel. It sends to idle
+// before waiting for the next chunk.
+type chanReader struct {
+	in   chan []byte
+	idle chan struct{}
+	buf  []byte
+	eof  bool
+}
+
+func (r *chanReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		r.idle <- struct{}{}
+		var ok bool
+		if r.buf, ok = <-r.in; !ok {
+			r.eof = true
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package json2yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestStream(t *testing.T) {
+	testCases := []struct {
+		name   string
+		chunks []string
+		want   []string
+		docs   []int
+		err    string
+	}{
+		{
+			name:   "object",
+			chunks: []string{`{"foo":`, ` 128, "ba`, `r": [nu`, `ll, true]}`, ``},
+			want:   []string{"foo:", "foo: 128\n", "foo: 128\nbar:\n  - ", "foo: 128\nbar:\n  - null\n  - true\n", "foo: 128\nbar:\n  - null\n  - true\n"},
+			docs:   []int{0, 0, 0, 1, 1},
+		},
+		{
+			name:   "multiple values",
+			chunks: []string{`1 2`, ` "foo`, `" [`, `]`},
+			want:   []string{"1\n---\n", "1\n---\n2\n---\n", "1\n---\n2\n---\nfoo\n---\n", "1\n---\n2\n---\nfoo\n---\n[]\n", "1\n---\n2\n---\nfoo\n---\n[]\n"},
+			docs:   []int{1, 2, 3, 4, 4},
+		},
+		{
+			name:   "unclosed object",
+			chunks: []string{`{"foo":`, `[1`},
+			want:   []string{"foo:", "foo:\n  - ", "foo:\n  - 1\n"},
+			docs:   []int{0, 0, 0},
+			err:    "unexpected EOF",
+		},
+		{
+			name:   "invalid character",
+			chunks: []string{`[1,`, `%]`, `[2]`},
+			want:   []string{"- 1\n- ", "- 1\n- \n", "- 1\n- \n", "- 1\n- \n"},
+			docs:   []int{0, 0, 0, 0},
+			err:    "invalid character '%'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			s := json2yaml.NewStream(&sb)
+			var err error
+			for i, chunk := range tc.chunks {
+				if err == nil {
+					err = s.Push([]byte(chunk))
+				}
+				if err == nil {
+					err = s.Flush()
+				}
+				if got := sb.String(); got != tc.want[i] {
+					t.Fatalf("should write\n  %q\nbut got\n  %q", tc.want[i], got)
+				}
+				if got := s.Documents(); got != tc.docs[i] {
+					t.Fatalf("should convert %d documents but got %d", tc.docs[i], got)
+				}
+			}
+			if cerr := s.Close(); err == nil {
+				err = cerr
+			}
+			if got, want := sb.String(), tc.want[len(tc.want)-1]; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
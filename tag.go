@@ -0,0 +1,33 @@
+package json2yaml
+
+import "path"
+
+// pathTag is a tag for the values at the paths matching the pattern.
+type pathTag struct {
+	pattern []string
+	tag     string
+}
+
+// tagFor returns the tag of the value at the path.
+func (c *converter) tagFor(p []string) string {
+	for _, t := range c.tags {
+		if matchPath(t.pattern, p) {
+			return t.tag
+		}
+	}
+	return ""
+}
+
+// matchPath reports whether the path matches the pattern, whose elements are
+// in the syntax of path.Match.
+func matchPath(pattern, p []string) bool {
+	if len(pattern) != len(p) {
+		return false
+	}
+	for i, k := range pattern {
+		if matched, _ := path.Match(k, p[i]); !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package json2yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithTag(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		opts []json2yaml.Option
+		want string
+	}{
+		{
+			name: "scalars",
+			src:  `{"data":{"a":"Zm9v","b":"YmFy"},"name":"x"}`,
+			opts: []json2yaml.Option{json2yaml.WithTag("/data/*", "!!binary")},
+			want: "data:\n  a: !!binary Zm9v\n  b: !!binary YmFy\nname: x\n",
+		},
+		{
+			name: "array elements",
+			src:  `{"steps":[{"run":"echo ${x}"},{"uses":"foo"},{"run":"a\nb"}]}`,
+			opts: []json2yaml.Option{json2yaml.WithTag("steps.*.run", "!Sub")},
+			want: "steps:\n  - run: !Sub echo ${x}\n  - uses: foo\n  - run: !Sub |-\n      a\n      b\n",
+		},
+		{
+			name: "collections",
+			src:  `{"a":{"x":1},"b":[1,[2]],"c":{},"d":[]} [{"y":2},[],[3]]`,
+			opts: []json2yaml.Option{
+				json2yaml.WithTag("/*", "!t"),
+				json2yaml.WithTag("", "!top"),
+				json2yaml.WithTag("/b/1", "!u"),
+			},
+			want: `!top
+a: !t
+  x: 1
+b: !t
+  - 1
+  - !u
+    - 2
+c: !t {}
+d: !t []
+---
+!top
+- !t
+  "y": 2
+- !t []
+- !t
+  - 3
+`,
+		},
+		{
+			name: "first match",
+			src:  `{"a":1,"b":2}`,
+			opts: []json2yaml.Option{json2yaml.WithTag("/a", "!a"), json2yaml.WithTag("/*", "!any")},
+			want: "a: !a 1\nb: !any 2\n",
+		},
+		{
+			name: "tag directives",
+			src:  `{"a":1} "b" [2]`,
+			opts: []json2yaml.Option{
+				json2yaml.WithTag("/a", "!e!foo"),
+				json2yaml.WithTagDirective("!e!", "tag:example.com,2000:app/"),
+			},
+			want: `%TAG !e! tag:example.com,2000:app/
+---
+a: !e!foo 1
+...
+%TAG !e! tag:example.com,2000:app/
+---
+b
+...
+%TAG !e! tag:example.com,2000:app/
+---
+- 2
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src), tc.opts...); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
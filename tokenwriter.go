@@ -0,0 +1,205 @@
+package json2yaml
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// TokenWriter writes YAML from a sequence of JSON tokens. This is useful to
+// generate YAML programmatically without producing intermediate JSON text.
+// Multiple values are written as separate documents. Once an error occurs,
+// the YAML written so far is flushed, and all the subsequent method calls
+// return the same error.
+type TokenWriter struct {
+	c   *converter
+	err error
+}
+
+// NewTokenWriter returns a new TokenWriter writing YAML to w. The options are
+// applied to the tokens in the same way as Convert (e.g. WithRedactKeys).
+func NewTokenWriter(w io.Writer, opts ...Option) *TokenWriter {
+	return &TokenWriter{c: newConverter(w, opts)}
+}
+
+// WriteToken writes a token. The token should be one of json.Delim for the
+// four JSON delimiters, bool, float64, json.Number, string, or nil, as
+// returned by json.Decoder.Token. A string token at the position of an
+// object key is written as the key.
+func (w *TokenWriter) WriteToken(t json.Token) error {
+	if w.err != nil {
+		return w.err
+	}
+	err := w.validate(t)
+	if err == nil {
+		_, err = w.c.putToken(t)
+	}
+	if err != nil {
+		return w.fail(err)
+	}
+	return nil
+}
+
+func (w *TokenWriter) fail(err error) error {
+	w.err = w.c.finish(err)
+	return w.err
+}
+
+// atKey reports whether the next token is at the position of an object key.
+func (w *TokenWriter) atKey() bool {
+	if w.c.paths != nil {
+		return w.c.paths.atKey()
+	}
+	switch w.c.stack[len(w.c.stack)-1] {
+	case '{':
+		return true
+	case ':':
+		return w.c.pending == pendingNext
+	default:
+		return false
+	}
+}
+
+// inArray reports whether the next token is in an array.
+func (w *TokenWriter) inArray() bool {
+	if w.c.paths != nil {
+		kinds := w.c.paths.kinds
+		return len(kinds) > 0 && kinds[len(kinds)-1] == '['
+	}
+	return w.c.stack[len(w.c.stack)-1] == '['
+}
+
+// depth returns the number of the objects and arrays not closed.
+func (w *TokenWriter) depth() int {
+	if w.c.paths != nil {
+		return len(w.c.paths.kinds)
+	}
+	return len(w.c.stack) - 1
+}
+
+func (w *TokenWriter) validate(t json.Token) error {
+	key := w.atKey()
+	switch t := t.(type) {
+	case json.Delim:
+		switch t {
+		case '{', '[':
+			if !key {
+				return nil
+			}
+		case '}':
+			if key {
+				return nil
+			}
+		case ']':
+			if w.inArray() {
+				return nil
+			}
+		}
+	case string:
+		return nil
+	default:
+		if !key && validScalar(t) {
+			return nil
+		}
+	}
+	if key {
+		return fmt.Errorf("json2yaml: invalid token %#v for object key", t)
+	}
+	return fmt.Errorf("json2yaml: invalid token %#v", t)
+}
+
+// validScalar reports whether the value can be written as a scalar.
+func validScalar(v any) bool {
+	switch v := v.(type) {
+	case nil, bool, string:
+		return true
+	case json.Number:
+		// The number out of the range of float64 (e.g. 1e400) is valid as JSON.
+		return v != "" && (v[0] == '-' || '0' <= v[0] && v[0] <= '9') &&
+			'0' <= v[len(v)-1] && v[len(v)-1] <= '9' && json.Valid([]byte(v))
+	case float64:
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	}
+	return false
+}
+
+// BeginObject writes the start of an object.
+func (w *TokenWriter) BeginObject() error {
+	return w.WriteToken(json.Delim('{'))
+}
+
+// EndObject writes the end of an object.
+func (w *TokenWriter) EndObject() error {
+	return w.WriteToken(json.Delim('}'))
+}
+
+// BeginArray writes the start of an array.
+func (w *TokenWriter) BeginArray() error {
+	return w.WriteToken(json.Delim('['))
+}
+
+// EndArray writes the end of an array.
+func (w *TokenWriter) EndArray() error {
+	return w.WriteToken(json.Delim(']'))
+}
+
+// Key writes an object key.
+func (w *TokenWriter) Key(k string) error {
+	if w.err == nil && !w.atKey() {
+		return w.fail(errors.New("json2yaml: unexpected object key"))
+	}
+	return w.WriteToken(k)
+}
+
+// String writes a string value.
+func (w *TokenWriter) String(s string) error {
+	if w.err == nil && w.atKey() {
+		return w.fail(errors.New("json2yaml: unexpected string value for object key"))
+	}
+	return w.WriteToken(s)
+}
+
+// Number writes a number value.
+func (w *TokenWriter) Number(n json.Number) error {
+	return w.WriteToken(n)
+}
+
+// Bool writes a boolean value.
+func (w *TokenWriter) Bool(b bool) error {
+	return w.WriteToken(b)
+}
+
+// Null writes a null value.
+func (w *TokenWriter) Null() error {
+	return w.WriteToken(nil)
+}
+
+// Flush writes the buffered YAML to the underlying writer, and flushes the
+// writer if it implements Flush.
+func (w *TokenWriter) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.c.flushDocument()
+	return w.err
+}
+
+// Close writes the remaining YAML. It returns io.ErrUnexpectedEOF if an object
+// or an array is not closed. Close does not close the underlying writer.
+func (w *TokenWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	err := io.EOF
+	if w.depth() > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	w.err = w.c.finish(err)
+	if w.err == nil {
+		w.err = errors.New("json2yaml: TokenWriter is closed")
+		return nil
+	}
+	return w.err
+}
@@ -0,0 +1,207 @@
+package json2yaml_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestTokenWriter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tokens []json.Token
+		opts   []json2yaml.Option
+		want   string
+		err    string
+	}{
+		{
+			name:   "scalars",
+			tokens: []json.Token{nil, false, json.Number("128"), -3.5, 1e-7, 1e21, "foo", "true", "a\nb\n"},
+			want:   join([]string{"null", "false", "128", "-3.5", "1e-7", "1e+21", "foo", `"true"`, "|\n  a\n  b"}),
+		},
+		{
+			name: "nested object and array",
+			tokens: []json.Token{
+				json.Delim('{'), "foo", json.Delim('['), json.Number("0"),
+				json.Delim('{'), "bar", json.Delim('['), json.Delim(']'), "foo", json.Delim('{'), json.Delim('}'), json.Delim('}'),
+				json.Delim('['), json.Delim('{'), "foo", json.Delim('['), json.Delim('{'), "foo", json.Delim('['), json.Delim(']'),
+				json.Delim('}'), json.Delim(']'), json.Delim('}'), json.Delim(']'),
+				json.Delim('['), json.Delim('['), json.Delim('['), json.Delim('{'), json.Delim('}'), json.Delim(']'), json.Delim(']'), json.Delim(']'),
+				json.Delim(']'), "bar", json.Delim('['), json.Delim('{'), json.Delim('}'), json.Delim(']'), json.Delim('}'),
+			},
+			want: `foo:
+  - 0
+  - bar: []
+    foo: {}
+  - - foo:
+        - foo: []
+  - - - - {}
+bar:
+  - {}
+`,
+		},
+		{
+			name: "block style string key",
+			tokens: []json.Token{
+				json.Delim('{'), "a\nb", json.Delim('['), "x", json.Delim(']'), "c", "d\ne", json.Delim('}'),
+			},
+			want: "? |-\n  a\n  b\n:\n  - x\nc: |-\n  d\n  e\n",
+		},
+		{
+			name:   "multiple documents",
+			tokens: []json.Token{json.Delim('{'), json.Delim('}'), json.Delim('['), json.Delim(']'), json.Number("1")},
+			want:   join([]string{"{}", "[]", "1"}),
+		},
+		{
+			name: "with redact keys",
+			tokens: []json.Token{
+				json.Delim('{'), "user", "foo", "password", "hunter2", "token", json.Delim('['), json.Delim('{'), json.Delim('}'), json.Delim(']'),
+				"x", json.Delim('{'), "vault.io/api-token", "bar", json.Delim('}'), json.Delim('}'),
+			},
+			opts: []json2yaml.Option{json2yaml.WithRedactKeys("*password*", "*token*")},
+			want: "user: foo\npassword: \"***\"\ntoken: \"***\"\nx:\n  vault.io/api-token: \"***\"\n",
+		},
+		{
+			name: "with redact keys containing invalid bytes",
+			tokens: []json.Token{
+				json.Delim('{'), "a\xffb", "foo", "a/b", "bar", "c\xffd", "baz", json.Delim('}'),
+			},
+			opts: []json2yaml.Option{json2yaml.WithRedactKeys("a/b", "c?d")},
+			want: "a\xffb: foo\na/b: \"***\"\nc\xffd: \"***\"\n",
+		},
+		{
+			name:   "with path",
+			tokens: []json.Token{json.Delim('{'), "a", json.Number("1"), "b", json.Delim('['), json.Number("2"), json.Delim(']'), json.Delim('}')},
+			opts:   []json2yaml.Option{json2yaml.WithPath("b")},
+			want:   "- 2\n",
+		},
+		{
+			name:   "unexpected end of object with redact keys",
+			tokens: []json.Token{json.Delim('{'), "password", json.Delim('}')},
+			opts:   []json2yaml.Option{json2yaml.WithRedactKeys("password")},
+			want:   "password:\n",
+			err:    "invalid token",
+		},
+		{
+			name:   "unclosed object",
+			tokens: []json.Token{json.Delim('{'), "foo", json.Delim('[')},
+			want:   "foo:\n",
+			err:    "unexpected EOF",
+		},
+		{
+			name:   "unexpected object key",
+			tokens: []json.Token{json.Delim('{'), json.Number("1")},
+			want:   "",
+			err:    "invalid token",
+		},
+		{
+			name:   "unexpected end of object",
+			tokens: []json.Token{json.Delim('{'), "foo", json.Delim('}')},
+			want:   "foo:\n",
+			err:    "invalid token",
+		},
+		{
+			name:   "unexpected end of array",
+			tokens: []json.Token{json.Delim('['), json.Delim('}')},
+			want:   "",
+			err:    "invalid token",
+		},
+		{
+			name:   "invalid number",
+			tokens: []json.Token{json.Number("01")},
+			want:   "",
+			err:    "invalid token",
+		},
+		{
+			name:   "large number",
+			tokens: []json.Token{json.Number("1e400")},
+			want:   "1e400\n",
+		},
+		{
+			name:   "string as number",
+			tokens: []json.Token{json.Number(`"1"`)},
+			want:   "",
+			err:    "invalid token",
+		},
+		{
+			name:   "invalid token",
+			tokens: []json.Token{1},
+			want:   "",
+			err:    "invalid token",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			w := json2yaml.NewTokenWriter(&sb, tc.opts...)
+			var err error
+			for _, token := range tc.tokens {
+				if err = w.WriteToken(token); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = w.Close()
+			}
+			if got, want := diff(sb.String(), tc.want); got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenWriterMethods(t *testing.T) {
+	var sb strings.Builder
+	w := json2yaml.NewTokenWriter(&sb)
+	for _, f := range []func() error{
+		w.BeginObject,
+		func() error { return w.Key("foo") },
+		w.BeginArray,
+		func() error { return w.String("bar") },
+		func() error { return w.Number("1.0") },
+		func() error { return w.Bool(true) },
+		w.Null,
+		w.EndArray,
+		func() error { return w.Key("baz") },
+		w.BeginObject,
+		w.EndObject,
+		w.EndObject,
+		w.Close,
+	} {
+		if err := f(); err != nil {
+			t.Fatalf("should not raise an error but got: %s", err)
+		}
+	}
+	if got, want := sb.String(), "foo:\n  - bar\n  - 1.0\n  - true\n  - null\nbaz: {}\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if err := w.Key("foo"); err == nil {
+		t.Fatalf("should raise an error but got no error")
+	}
+
+	w = json2yaml.NewTokenWriter(&sb)
+	if err := w.Key("foo"); err == nil || !strings.Contains(err.Error(), "unexpected object key") {
+		t.Fatalf("should raise an error but got: %v", err)
+	}
+	w = json2yaml.NewTokenWriter(&sb)
+	if err := w.BeginObject(); err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if err := w.String("foo"); err == nil || !strings.Contains(err.Error(), "unexpected string value") {
+		t.Fatalf("should raise an error but got: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package json2yaml
+
+import "strconv"
+
+// truncateString returns the first n characters of the string, and reports
+// whether the string is truncated.
+func truncateString(s string, n int) (string, bool) {
+	for i := range s {
+		if n == 0 {
+			return s[:i], true
+		}
+		n--
+	}
+	return s, false
+}
+
+// formatSize formats the size in bytes in a human-readable form.
+func formatSize(n int) string {
+	if n < 1024 {
+		return strconv.Itoa(n) + "B"
+	}
+	size, unit := float64(n)/1024, "KB"
+	for _, u := range []string{"MB", "GB"} {
+		if size < 1024 {
+			break
+		}
+		size, unit = size/1024, u
+	}
+	return strconv.FormatFloat(size, 'f', 1, 64) + unit
+}
@@ -0,0 +1,65 @@
+package json2yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestConvertWithTruncate(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		n    int
+		want string
+	}{
+		{
+			name: "short strings",
+			src:  `{"foo":"bar","baz":[1,true,null]}`,
+			n:    3,
+			want: "foo: bar\nbaz:\n  - 1\n  - true\n  - null\n",
+		},
+		{
+			name: "long strings",
+			src:  `{"foo":"abcdefghij","bar":["` + strings.Repeat("x", 1300000) + `"]}`,
+			n:    4,
+			want: "foo: abcd # truncated, 10B\nbar:\n  - xxxx # truncated, 1.2MB\n",
+		},
+		{
+			name: "multibyte characters",
+			src:  `"あいうえお"`,
+			n:    2,
+			want: "あい # truncated, 15B\n",
+		},
+		{
+			name: "quoted string",
+			src:  `{"foo":"true and false"}`,
+			n:    4,
+			want: "foo: \"true\" # truncated, 14B\n",
+		},
+		{
+			name: "multi-line string",
+			src:  `{"foo":"abc\ndef\nghi\n"}`,
+			n:    6,
+			want: "foo: |- # truncated, 12B\n  abc\n  de\n",
+		},
+		{
+			name: "long key",
+			src:  `{"abcdefghij":"abcdefghij"}`,
+			n:    4,
+			want: "abcdefghij: abcd # truncated, 10B\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithTruncate(tc.n)); err != nil {
+				t.Fatalf("should not raise an error but got: %s", err)
+			}
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+		})
+	}
+}
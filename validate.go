@@ -0,0 +1,296 @@
+package json2yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate reads JSON values from r, and returns *ParseError on the first
+// invalid input. It is faster than Convert as it does not write YAML. The
+// options other than WithDecompress are ignored.
+func Validate(r io.Reader, opts ...Option) error {
+	if newConverter(nil, opts).decompress {
+		r = &decompressReader{r: r}
+	}
+	t := &tracker{r: r, pos: Position{Line: 1, Column: 1}}
+	dec := json.NewDecoder(t)
+	for {
+		var m json.RawMessage
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return &ParseError{Pos: t.errorPosition(err, dec.InputOffset()), Err: err}
+		}
+		t.commit(dec.InputOffset())
+	}
+}
+
+// ValidationError is an error of the schema validation.
+type ValidationError struct {
+	Path string // JSON Pointer to the invalid value
+	Msg  string
+}
+
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("schema validation failed at %q: %s", err.Path, err.Msg)
+}
+
+// validator validates the tokens against the schema in streaming fashion.
+type validator struct {
+	schema  *Schema
+	handler func(error) error
+	frames  []validationFrame
+}
+
+// validationFrame is the state of an object or an array being validated.
+type validationFrame struct {
+	kind    byte
+	schemas []*Schema
+	count   int
+	keys    map[string]bool
+}
+
+// next validates the token with the path returned by pathTracker.next.
+func (v *validator) next(token json.Token, path []string, key bool) error {
+	n := len(v.frames)
+	if key {
+		f := &v.frames[n-1]
+		f.count++
+		if f.keys != nil {
+			f.keys[token.(string)] = true
+		}
+		return nil
+	}
+	if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+		f := v.frames[n-1]
+		v.frames = v.frames[:n-1]
+		return v.validateCollection(&f, path)
+	}
+	var schemas []*Schema
+	if n == 0 {
+		schemas = appendSchemas(nil, v.schema)
+	} else {
+		f := &v.frames[n-1]
+		for _, s := range f.schemas {
+			if f.kind == '[' {
+				schemas = appendSchemas(schemas, s.Items)
+			} else {
+				schemas = appendSchemas(schemas, s.property(path[len(path)-1]))
+			}
+		}
+		if f.kind == '[' {
+			f.count++
+		}
+	}
+	for _, s := range schemas {
+		if msg := validateValue(s, token); msg != "" {
+			if err := v.report(path, msg); err != nil {
+				return err
+			}
+		}
+	}
+	if delim, ok := token.(json.Delim); ok {
+		f := validationFrame{kind: byte(delim), schemas: schemas}
+		for _, s := range schemas {
+			if len(s.Required) > 0 {
+				f.keys = make(map[string]bool)
+				break
+			}
+		}
+		v.frames = append(v.frames, f)
+	}
+	return nil
+}
+
+func (v *validator) report(path []string, msg string) error {
+	var sb strings.Builder
+	for _, k := range path {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(k))
+	}
+	err := error(&ValidationError{Path: sb.String(), Msg: msg})
+	if v.handler != nil {
+		return v.handler(err)
+	}
+	return err
+}
+
+// appendSchemas appends the schema and the schemas in allOf.
+func appendSchemas(schemas []*Schema, s *Schema) []*Schema {
+	if s = s.resolve(); s == nil {
+		return schemas
+	}
+	schemas = append(schemas, s)
+	for _, t := range s.AllOf {
+		schemas = appendSchemas(schemas, t)
+	}
+	return schemas
+}
+
+func (v *validator) validateCollection(f *validationFrame, path []string) error {
+	for _, s := range f.schemas {
+		var msgs []string
+		for _, k := range s.Required {
+			if !f.keys[k] {
+				msgs = append(msgs, fmt.Sprintf("missing required property %q", k))
+			}
+		}
+		lo, hi, name := s.MinProperties, s.MaxProperties, "properties"
+		if f.kind == '[' {
+			lo, hi, name = s.MinItems, s.MaxItems, "items"
+		}
+		if lo != nil && f.count < *lo {
+			msgs = append(msgs, fmt.Sprintf("expected at least %d %s but got %d", *lo, name, f.count))
+		}
+		if hi != nil && f.count > *hi {
+			msgs = append(msgs, fmt.Sprintf("expected at most %d %s but got %d", *hi, name, f.count))
+		}
+		for _, msg := range msgs {
+			if err := v.report(path, msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateValue validates the token starting a value, and returns the message
+// describing the violation.
+func validateValue(s *Schema, token json.Token) string {
+	if s.never {
+		return "value is not allowed"
+	}
+	typ := typeOf(token)
+	if len(s.Type) > 0 {
+		var ok bool
+		for _, t := range s.Type {
+			if ok = t == typ || t == "number" && typ == "integer"; ok {
+				break
+			}
+		}
+		if !ok {
+			return fmt.Sprintf("expected %s but got %s", strings.Join(s.Type, " or "), typ)
+		}
+	}
+	if len(s.Enum) > 0 && typ != "object" && typ != "array" {
+		var ok bool
+		for _, e := range s.Enum {
+			if ok = equalScalar(e, token); ok {
+				break
+			}
+		}
+		if !ok {
+			return fmt.Sprintf("value %s is not in the enum", formatToken(token))
+		}
+	}
+	if f, ok := toFloat(token); ok {
+		if s.Minimum != nil && f < *s.Minimum {
+			return fmt.Sprintf("expected %s >= %v", formatToken(token), *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fmt.Sprintf("expected %s <= %v", formatToken(token), *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+			return fmt.Sprintf("expected %s > %v", formatToken(token), *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+			return fmt.Sprintf("expected %s < %v", formatToken(token), *s.ExclusiveMaximum)
+		}
+	}
+	if token, ok := token.(string); ok {
+		n := utf8.RuneCountInString(token)
+		if s.MinLength != nil && n < *s.MinLength {
+			return fmt.Sprintf("expected length >= %d but got %d", *s.MinLength, n)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return fmt.Sprintf("expected length <= %d but got %d", *s.MaxLength, n)
+		}
+		if s.Pattern != "" {
+			re, err := s.compilePattern()
+			if err != nil {
+				return err.Error()
+			}
+			if !re.MatchString(token) {
+				return fmt.Sprintf("%q does not match pattern %q", token, s.Pattern)
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Schema) compilePattern() (*regexp.Regexp, error) {
+	if s.pattern == nil {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("json2yaml: invalid pattern in schema: %w", err)
+		}
+		s.pattern = re
+	}
+	return s.pattern, nil
+}
+
+// typeOf returns the JSON Schema type of the token starting a value.
+func typeOf(token json.Token) string {
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '{' {
+			return "object"
+		}
+		return "array"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if f, err := token.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case float64:
+		if token == math.Trunc(token) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	default:
+		return "null"
+	}
+}
+
+func equalScalar(e any, token json.Token) bool {
+	if f, ok := toFloat(e); ok {
+		g, ok := toFloat(token)
+		return ok && f == g
+	}
+	return e == token
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func formatToken(token json.Token) string {
+	switch token := token.(type) {
+	case json.Number:
+		return string(token)
+	case string:
+		return strconv.Quote(token)
+	default:
+		bs, _ := json.Marshal(token)
+		return string(bs)
+	}
+}
@@ -0,0 +1,201 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		src string
+		err string
+	}{
+		{``, ""},
+		{`{"foo": [1, 2.5, true, null, "bar"]} [] "baz"`, ""},
+		{"{\n  \"foo\": [1,\n  x]\n}", "line 3, column 3: invalid character 'x' looking for beginning of value"},
+		{"[1,2] [3,4]\n{\"foo\": tru}", "line 2, column 12: invalid character '}' in literal true (expecting 'e')"},
+		{`{"foo" 1}`, "line 1, column 8: invalid character '1' after object key"},
+		{"[1,2]\n[3,\n", "line 3, column 1: unexpected EOF"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			err := json2yaml.Validate(strings.NewReader(tc.src))
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+				return
+			}
+			var perr *json2yaml.ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("should raise *json2yaml.ParseError but got %v", err)
+			}
+			if got, want := err.Error(), tc.err; got != want {
+				t.Fatalf("should raise an error %q but got error %q", want, got)
+			}
+		})
+	}
+}
+
+const testValidationSchema = `{
+  "type": "object",
+  "required": ["name"],
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 8},
+    "replicas": {"type": "integer", "minimum": 1, "exclusiveMaximum": 10},
+    "ratio": {"type": ["number", "null"]},
+    "policy": {"enum": ["Always", "Never", 0]},
+    "ports": {"type": "array", "minItems": 1, "items": {"$ref": "#/$defs/port"}}
+  },
+  "$defs": {
+    "port": {"allOf": [{"type": "object", "required": ["port"]}, {"maxProperties": 1}]}
+  }
+}`
+
+func TestConvertWithSchemaValidation(t *testing.T) {
+	schema, err := json2yaml.ParseSchema(strings.NewReader(testValidationSchema))
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "valid",
+			src:  `{"name":"app","replicas":3,"ratio":null,"policy":0,"ports":[{"port":80}]}`,
+			want: `name: app
+replicas: 3
+ratio: null
+policy: 0
+ports:
+  - port: 80
+`,
+		},
+		{
+			name: "type",
+			src:  `{"name":"app","replicas":1.5}`,
+			want: "name: app\nreplicas:\n",
+			err:  `schema validation failed at "/replicas": expected integer but got number`,
+		},
+		{
+			name: "type of collection",
+			src:  `[1]`,
+			err:  `schema validation failed at "": expected object but got array`,
+		},
+		{
+			name: "pattern",
+			src:  `{"name":"App"}`,
+			want: "name:\n",
+			err:  `schema validation failed at "/name": "App" does not match pattern "^[a-z]+$"`,
+		},
+		{
+			name: "max length",
+			src:  `{"name":"application"}`,
+			want: "name:\n",
+			err:  `schema validation failed at "/name": expected length <= 8 but got 11`,
+		},
+		{
+			name: "minimum",
+			src:  `{"name":"app","replicas":0}`,
+			want: "name: app\nreplicas:\n",
+			err:  `schema validation failed at "/replicas": expected 0 >= 1`,
+		},
+		{
+			name: "exclusive maximum",
+			src:  `{"name":"app","replicas":10}`,
+			want: "name: app\nreplicas:\n",
+			err:  `schema validation failed at "/replicas": expected 10 < 10`,
+		},
+		{
+			name: "enum",
+			src:  `{"name":"app","policy":"IfNotPresent"}`,
+			want: "name: app\npolicy:\n",
+			err:  `schema validation failed at "/policy": value "IfNotPresent" is not in the enum`,
+		},
+		{
+			name: "additional properties",
+			src:  `{"name":"app","unknown":true}`,
+			want: "name: app\nunknown:\n",
+			err:  `schema validation failed at "/unknown": value is not allowed`,
+		},
+		{
+			name: "required",
+			src:  `{"replicas":1}`,
+			want: "replicas: 1\n",
+			err:  `schema validation failed at "": missing required property "name"`,
+		},
+		{
+			name: "min items",
+			src:  `{"name":"app","ports":[]}`,
+			want: "name: app\nports: []\n",
+			err:  `schema validation failed at "/ports": expected at least 1 items but got 0`,
+		},
+		{
+			name: "all of",
+			src:  `{"name":"app","ports":[{"port":80},{"name":"http"}]}`,
+			want: "name: app\nports:\n  - port: 80\n  - name: http\n",
+			err:  `schema validation failed at "/ports/1": missing required property "port"`,
+		},
+		{
+			name: "multiple documents",
+			src:  `{"name":"a"} {"name":"b","ports":[{"port":80,"protocol":"TCP"}]}`,
+			want: "name: a\n---\nname: b\nports:\n  - port: 80\n    protocol: TCP\n",
+			err:  `schema validation failed at "/ports/0": expected at most 1 properties but got 2`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := json2yaml.Convert(&sb, strings.NewReader(tc.src), json2yaml.WithSchemaValidation(schema, nil))
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if got, want := err.Error(), tc.err; got != want {
+					t.Fatalf("should raise an error %q but got error %q", want, got)
+				}
+				var verr *json2yaml.ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("should raise *json2yaml.ValidationError but got %T", err)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithSchemaValidationHandler(t *testing.T) {
+	schema, err := json2yaml.ParseSchema(strings.NewReader(testValidationSchema))
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	var sb strings.Builder
+	var paths []string
+	err = json2yaml.Convert(&sb, strings.NewReader(`{"name":"App","replicas":0,"ports":[{}]}`),
+		json2yaml.WithSchemaValidation(schema, func(err error) error {
+			paths = append(paths, err.(*json2yaml.ValidationError).Path)
+			return nil
+		}))
+	if err != nil {
+		t.Fatalf("should not raise an error but got: %s", err)
+	}
+	if got, want := sb.String(), "name: App\nreplicas: 0\nports:\n  - {}\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+	if got, want := strings.Join(paths, " "), "/name /replicas /ports/0"; got != want {
+		t.Fatalf("should report %q but got %q", want, got)
+	}
+}
@@ -0,0 +1,67 @@
+package json2yaml
+
+import "io"
+
+// NewWriter returns a writer which accepts JSON and writes YAML to w. The
+// JSON can be written in arbitrary chunks, and the conversion is finalized on
+// Close, which returns the error of the conversion.
+func NewWriter(w io.Writer, opts ...Option) io.WriteCloser {
+	pr, pw := io.Pipe()
+	cw := &writer{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(cw.done)
+		cw.err = Convert(w, pr, opts...)
+		pr.CloseWithError(cw.err)
+	}()
+	return cw
+}
+
+type writer struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+	err  error
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.pw.Write(p)
+	if err != nil {
+		<-w.done
+		if w.err != nil {
+			err = w.err
+		}
+	}
+	return n, err
+}
+
+func (w *writer) Close() error {
+	w.pw.Close()
+	<-w.done
+	return w.err
+}
+
+// DocumentWriter writes YAML documents converted from multiple inputs to one
+// stream, inserting separators between the documents of each call.
+type DocumentWriter struct {
+	w       io.Writer
+	opts    []Option
+	written bool
+	size    int64 // number of bytes written to w
+}
+
+// NewDocumentWriter returns a new DocumentWriter writing YAML to w.
+func NewDocumentWriter(w io.Writer, opts ...Option) *DocumentWriter {
+	return &DocumentWriter{w: w, opts: opts}
+}
+
+// Convert reads JSON from r and appends the YAML documents to the stream.
+func (w *DocumentWriter) Convert(r io.Reader) error {
+	c := newConverter(w.w, w.opts)
+	c.written = w.size
+	if w.written {
+		c.pending = pendingNext
+	}
+	err := c.convert(r)
+	w.written = w.written || c.last != 0
+	w.size = c.written
+	return err
+}
@@ -0,0 +1,132 @@
+package json2yaml_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/itchyny/json2yaml"
+)
+
+func TestNewWriter(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "nested object and array",
+			src:  `{"foo":[0,{"bar":[],"foo":{}},[{"foo":[{"foo":[]}]}],[[[{}]]]],"bar":[{}]}`,
+			want: `foo:
+  - 0
+  - bar: []
+    foo: {}
+  - - foo:
+        - foo: []
+  - - - - {}
+bar:
+  - {}
+`,
+		},
+		{
+			name: "multiple values",
+			src:  `{"x": "a\nb\n"} [1, 2] "foo"`,
+			want: "x: |\n  a\n  b\n---\n- 1\n- 2\n---\nfoo\n",
+		},
+		{
+			name: "unclosed object after object value",
+			src:  `{"foo":128`,
+			want: "foo: 128\n",
+			err:  "unexpected EOF",
+		},
+		{
+			name: "unexpected character in array",
+			src:  "[1,%,2,3,4]",
+			want: "- 1\n- \n",
+			err:  "invalid character '%'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			w := json2yaml.NewWriter(&sb)
+			_, err := io.Copy(w, iotest.OneByteReader(strings.NewReader(tc.src)))
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+			if got := sb.String(); got != tc.want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", tc.want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentWriter(t *testing.T) {
+	var sb strings.Builder
+	w := json2yaml.NewDocumentWriter(&sb)
+	for _, src := range []string{`{"foo":128}`, ``, `1 2`, ` `, `[1,`, `"foo"`} {
+		err := w.Convert(strings.NewReader(src))
+		if src == `[1,` {
+			if err == nil || err.Error() != "unexpected EOF" {
+				t.Fatalf("should raise an error %q but got %v", "unexpected EOF", err)
+			}
+		} else if err != nil {
+			t.Fatalf("should not raise an error but got: %s", err)
+		}
+	}
+	if got, want := sb.String(), "foo: 128\n---\n1\n---\n2\n---\n- 1\n- \n---\nfoo\n"; got != want {
+		t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+	}
+}
+
+func TestDocumentWriterWriteError(t *testing.T) {
+	srcs := []string{
+		`{"foo":"` + strings.Repeat("x", 5000) + `"}`,
+		`{"bar":"` + strings.Repeat("y", 5000) + `"}`,
+		`{"baz":"` + strings.Repeat("z", 5000) + `"}`,
+	}
+	for _, n := range []int{3000, 8000, 13000} {
+		sb := &limitWriter{n: n}
+		w := json2yaml.NewDocumentWriter(sb)
+		var err error
+		for _, src := range srcs {
+			if err = w.Convert(strings.NewReader(src)); err != nil {
+				break
+			}
+		}
+		var werr *json2yaml.WriteError
+		if !errors.As(err, &werr) {
+			t.Fatalf("should raise *json2yaml.WriteError but got %v", err)
+		}
+		if got, want := werr.Written, int64(sb.Len()); got != want {
+			t.Fatalf("should report %d bytes written but got %d", want, got)
+		}
+		sb = &limitWriter{n: n}
+		rs := make([]io.Reader, len(srcs))
+		for i, src := range srcs {
+			rs[i] = strings.NewReader(src)
+		}
+		err = json2yaml.ConvertAll(sb, rs)
+		if !errors.As(err, &werr) {
+			t.Fatalf("should raise *json2yaml.WriteError but got %v", err)
+		}
+		if got, want := werr.Written, int64(sb.Len()); got != want {
+			t.Fatalf("should report %d bytes written but got %d", want, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package yaml2json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Decoder reads documents from a stream of YAML or JSON. The format is
+// detected from the input; a stream starting with '{' or '[' is read as JSON
+// values, and falls back to YAML if the first value is not valid JSON.
+type Decoder struct {
+	r         *bufio.Reader
+	p         *parser
+	dec       *json.Decoder
+	rec       *recordReader
+	buf       bytes.Buffer
+	doc       []byte
+	ready     bool
+	useNumber bool
+	err       error
+}
+
+// NewDecoder returns a new Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// UseNumber causes the Decoder to unmarshal a number into an interface value
+// as a json.Number instead of as a float64.
+func (d *Decoder) UseNumber() {
+	d.useNumber = true
+}
+
+// Next advances to the next document, and reports whether it exists. It
+// returns false at the end of the input or on an error, which Err returns.
+func (d *Decoder) Next() bool {
+	d.ready, d.doc = false, nil
+	if d.err != nil {
+		return false
+	}
+	if d.p == nil && d.dec == nil {
+		d.init()
+	}
+	if d.dec != nil {
+		var m json.RawMessage
+		if d.err = d.dec.Decode(&m); d.err != nil {
+			var serr *json.SyntaxError
+			if !d.rec.done && errors.As(d.err, &serr) {
+				d.dec, d.err = nil, nil
+				d.p = newParser(io.MultiReader(bytes.NewReader(d.rec.buf), d.r), &writer{w: &d.buf})
+				return d.Next()
+			}
+			if d.err == io.EOF {
+				d.err = nil
+			}
+			return false
+		}
+		d.rec.buf, d.rec.done = nil, true
+		d.doc, d.ready = m, true
+		return true
+	}
+	d.buf.Reset()
+	var ok bool
+	if ok, d.err = d.p.parseDocument(); !ok || d.err != nil {
+		return false
+	}
+	d.doc, d.ready = d.buf.Bytes(), true
+	return true
+}
+
+// init detects the format of the input.
+func (d *Decoder) init() {
+	for i := 1; ; i++ {
+		bs, _ := d.r.Peek(i)
+		if len(bs) < i {
+			break
+		}
+		if c := bs[i-1]; c == '{' || c == '[' {
+			d.rec = &recordReader{r: d.r}
+			d.dec = json.NewDecoder(d.rec)
+			return
+		} else if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			break
+		}
+	}
+	d.p = newParser(d.r, &writer{w: &d.buf})
+}
+
+// Decode stores the current document in the value pointed to by v. If Next
+// has not been called since the last Decode, it advances to the next document,
+// and returns io.EOF if there is none.
+func (d *Decoder) Decode(v any) error {
+	if !d.ready && !d.Next() {
+		if d.err != nil {
+			return d.err
+		}
+		return io.EOF
+	}
+	d.ready = false
+	dec := json.NewDecoder(bytes.NewReader(d.doc))
+	if d.useNumber {
+		dec.UseNumber()
+	}
+	return dec.Decode(v)
+}
+
+// Err returns the error encountered by Next.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// recordReader records the bytes read from the underlying reader until done.
+type recordReader struct {
+	r    io.Reader
+	buf  []byte
+	done bool
+}
+
+func (r *recordReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if !r.done {
+		r.buf = append(r.buf, p[:n]...)
+	}
+	return n, err
+}
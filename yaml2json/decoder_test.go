@@ -0,0 +1,97 @@
+package yaml2json_test
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml/yaml2json"
+)
+
+func TestDecoder(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want []any
+		err  string
+	}{
+		{
+			name: "empty",
+			src:  "",
+		},
+		{
+			name: "yaml documents",
+			src:  "a: 1\n---\n- foo\n- true\n--- null\n",
+			want: []any{map[string]any{"a": json.Number("1")}, []any{"foo", true}, nil},
+		},
+		{
+			name: "json values",
+			src:  ` {"a": 1} [2, "foo"] 3 "bar"`,
+			want: []any{map[string]any{"a": json.Number("1")}, []any{json.Number("2"), "foo"}, json.Number("3"), "bar"},
+		},
+		{
+			name: "yaml flow collection",
+			src:  "{a: 1, b: [x]}\n---\n[]",
+			want: []any{map[string]any{"a": json.Number("1"), "b": []any{"x"}}, []any{}},
+		},
+		{
+			name: "yaml error",
+			src:  "a: 1\n---\na: b: c\n",
+			want: []any{map[string]any{"a": json.Number("1")}},
+			err:  "line 3, column 4: mapping values are not allowed in this context",
+		},
+		{
+			name: "json error",
+			src:  `[1] [2,]`,
+			want: []any{[]any{json.Number("1")}},
+			err:  "invalid character ']'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dec := yaml2json.NewDecoder(strings.NewReader(tc.src))
+			dec.UseNumber()
+			var got []any
+			for dec.Next() {
+				var v any
+				if err := dec.Decode(&v); err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+				got = append(got, v)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("should decode\n  %#v\nbut got\n  %#v", tc.want, got)
+			}
+			if err := dec.Err(); tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderDecode(t *testing.T) {
+	dec := yaml2json.NewDecoder(strings.NewReader("foo: 1\n---\nfoo: 2\n"))
+	for _, want := range []int{1, 2} {
+		var v struct{ Foo int }
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("should not raise an error but got: %s", err)
+		}
+		if v.Foo != want {
+			t.Fatalf("should decode %d but got %d", want, v.Foo)
+		}
+	}
+	if err := dec.Decode(new(any)); err != io.EOF {
+		t.Fatalf("should return io.EOF but got: %v", err)
+	}
+}
@@ -0,0 +1,1136 @@
+// Package yaml2json implements a converter from YAML to JSON.
+package yaml2json
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Convert reads YAML from r and writes JSON to w. Each document in the YAML
+// stream is written as a JSON value followed by a newline.
+func Convert(w io.Writer, r io.Reader) error {
+	p := newParser(r, &writer{w: w})
+	err := p.parseStream()
+	if b := p.w.lastByte(); err != nil && b != 0 && b != '\n' {
+		p.w.buf.WriteByte('\n')
+	}
+	if ferr := p.w.flush(); ferr != nil && err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// SyntaxError represents an error in the YAML input.
+type SyntaxError struct {
+	Line   int // line number, starting at 1
+	Column int // column number in bytes, starting at 1
+	Msg    string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", err.Line, err.Column, err.Msg)
+}
+
+// token represents an event of the YAML stream.
+type token struct {
+	kind  byte // '{', '}', '[', ']', or 0 for a scalar
+	value string
+	style byte // 0 for plain, '"', '\'', '|', or '>'
+	tag   string
+}
+
+type parser struct {
+	r       *bufio.Reader
+	rerr    error
+	line    string
+	lineno  int
+	col     int
+	eof     bool
+	w       *writer
+	anchors map[string][]token
+	aliased int // number of tokens replayed by the aliases
+	read    int // number of bytes read from r
+	records []*[]token
+	capture *[]token
+	start   [2]int // line and column of the current node
+}
+
+func newParser(r io.Reader, w *writer) *parser {
+	p := &parser{r: bufio.NewReader(r), w: w, anchors: make(map[string][]token)}
+	p.nextLine()
+	p.line = strings.TrimPrefix(p.line, "\uFEFF")
+	return p
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.lineno, Column: p.col + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// nextLine reads the next line. Read errors are reported at the end.
+func (p *parser) nextLine() {
+	if p.eof {
+		return
+	}
+	s, err := p.r.ReadString('\n')
+	if err != nil {
+		if err != io.EOF {
+			p.rerr = err
+		}
+		if s == "" {
+			p.eof, p.line, p.col = true, "", 0
+			return
+		}
+	}
+	p.lineno++
+	p.read += len(s)
+	s = strings.TrimSuffix(s, "\n")
+	p.line, p.col = strings.TrimSuffix(s, "\r"), 0
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+func isFlowIndicator(b byte) bool {
+	return b == ',' || b == '[' || b == ']' || b == '{' || b == '}'
+}
+
+// peek returns the byte at the offset from the current column, or 0 at the
+// end of the line.
+func (p *parser) peek(i int) byte {
+	if i += p.col; i < len(p.line) {
+		return p.line[i]
+	}
+	return 0
+}
+
+// atIndicator reports whether the current byte is an indicator followed by
+// a white space or the end of the line.
+func (p *parser) atIndicator(b byte) bool {
+	return !p.eof && p.peek(0) == b && (p.peek(1) == 0 || isSpace(p.peek(1)))
+}
+
+func (p *parser) atComment() bool {
+	return p.peek(0) == '#' && (p.col == 0 || isSpace(p.line[p.col-1]))
+}
+
+func (p *parser) atDocumentMarker() bool {
+	return !p.eof && p.col == 0 && len(p.line) >= 3 &&
+		(p.line[:3] == "---" || p.line[:3] == "...") &&
+		(len(p.line) == 3 || isSpace(p.line[3]))
+}
+
+func (p *parser) skipSpaces() {
+	for p.col < len(p.line) && isSpace(p.line[p.col]) {
+		p.col++
+	}
+}
+
+// skipToContent skips white spaces and comments, and moves to the next
+// content line if nothing remains in the current line. It reports whether
+// it moved to another line.
+func (p *parser) skipToContent() bool {
+	var moved bool
+	for {
+		p.skipSpaces()
+		if p.eof || p.col < len(p.line) && !p.atComment() {
+			return moved
+		}
+		p.nextLine()
+		moved = true
+	}
+}
+
+// endLine ensures that nothing but comments remains in the current line,
+// and moves to the next content line.
+func (p *parser) endLine() error {
+	if p.skipSpaces(); p.col < len(p.line) && !p.atComment() {
+		return p.errorf("unexpected character %q", p.line[p.col])
+	}
+	p.skipToContent()
+	return nil
+}
+
+func (p *parser) parseStream() error {
+	for {
+		if ok, err := p.parseDocument(); !ok || err != nil {
+			return err
+		}
+	}
+}
+
+// parseDocument parses the next document, and reports whether it exists.
+func (p *parser) parseDocument() (bool, error) {
+	for !p.eof {
+		if p.skipToContent(); p.col == 0 && p.peek(0) == '%' {
+			p.nextLine() // ignore directives
+		} else if p.atDocumentMarker() && p.line[0] == '.' {
+			p.nextLine()
+		} else {
+			break
+		}
+	}
+	if p.eof {
+		return false, p.rerr
+	}
+	if p.atDocumentMarker() {
+		p.col = 3
+	}
+	if err := p.parseNode(-1, false, false); err != nil {
+		return true, err
+	}
+	if err := p.w.endDocument(); err != nil {
+		return true, err
+	}
+	if !p.eof && !p.atDocumentMarker() {
+		return true, p.errorf("unexpected content after the document")
+	}
+	return true, nil
+}
+
+// parseNode parses a node indented more than indent, or a node placed after
+// an indicator in the same line. The value flag reports whether the node is
+// the value of an implicit key, where block collections cannot start in the
+// same line. The seq flag reports whether a block sequence can be placed at
+// the same indentation. After parsing a node, the parser is positioned at the
+// next content line.
+func (p *parser) parseNode(indent int, value, seq bool) error {
+	inline := !p.skipToContent()
+	var anchor, tag string
+	var propsInline bool
+	for {
+		if p.eof || p.atDocumentMarker() || !inline && p.col <= indent &&
+			!(seq && p.col == indent && p.atIndicator('-')) {
+			return p.withAnchor(anchor, func() error {
+				return p.emit(token{tag: tag})
+			})
+		}
+		switch p.peek(0) {
+		case '&':
+			anchor = p.readName()
+		case '!':
+			tag = p.readName()
+		default:
+			p.start = [2]int{p.lineno, p.col}
+			return p.parseNodeContent(indent, value && inline, anchor, tag, propsInline)
+		}
+		propsInline = inline
+		if p.skipToContent() {
+			inline, propsInline = false, false
+		}
+	}
+}
+
+func (p *parser) parseNodeContent(indent int, value bool, anchor, tag string, propsInline bool) error {
+	switch c := p.peek(0); {
+	case p.atIndicator('-'), p.atIndicator('?'):
+		if value {
+			return p.errorf("block collection is not allowed in this context")
+		}
+		return p.withAnchor(anchor, func() error {
+			if c == '-' {
+				return p.parseBlockSequence(p.col)
+			}
+			return p.parseBlockMapping(p.col, "")
+		})
+	case c == '|' || c == '>':
+		return p.withAnchor(anchor, func() error {
+			return p.parseBlockScalar(indent, tag)
+		})
+	case c == '*':
+		if err := p.parseAlias(); err != nil {
+			return err
+		}
+		return p.endLine()
+	case c == '[' || c == '{':
+		if err := p.withAnchor(anchor, func() error {
+			return p.parseFlowCollection()
+		}); err != nil {
+			return err
+		}
+		return p.endLine()
+	case p.atImplicitKey():
+		if value {
+			return p.errorf("mapping values are not allowed in this context")
+		}
+		if propsInline {
+			return p.parseBlockMapping(p.col, anchor)
+		}
+		return p.withAnchor(anchor, func() error {
+			return p.parseBlockMapping(p.col, "")
+		})
+	case c == '"' || c == '\'':
+		if err := p.withAnchor(anchor, func() error {
+			s, err := p.parseQuoted()
+			if err != nil {
+				return err
+			}
+			return p.emit(token{value: s, style: c, tag: tag})
+		}); err != nil {
+			return err
+		}
+		return p.endLine()
+	default:
+		return p.withAnchor(anchor, func() error {
+			s, err := p.parsePlain(indent)
+			if err != nil {
+				return err
+			}
+			return p.emit(token{value: s, tag: tag})
+		})
+	}
+}
+
+// readName reads an anchor name or a tag.
+func (p *parser) readName() string {
+	start := p.col
+	for p.col++; p.col < len(p.line) && !isSpace(p.line[p.col]) &&
+		!isFlowIndicator(p.line[p.col]); p.col++ {
+	}
+	return p.line[start:p.col]
+}
+
+// The maximum number of tokens replayed by the aliases is aliasLimitMin plus
+// aliasLimitRatio times the input size.
+const (
+	aliasLimitMin   = 10000
+	aliasLimitRatio = 100
+)
+
+func (p *parser) parseAlias() error {
+	start := p.col
+	name := p.readName()
+	tokens, ok := p.anchors[name[1:]]
+	if !ok {
+		p.col = start
+		return p.errorf("unknown anchor %q", name[1:])
+	}
+	// Limit the expansion of the nested aliases (billion laughs attack).
+	if p.aliased += len(tokens); p.aliased > aliasLimitMin+aliasLimitRatio*p.read {
+		p.col = start
+		return p.errorf("too many tokens expanded by the aliases")
+	}
+	for _, t := range tokens {
+		if err := p.emit(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withAnchor calls f recording the emitted tokens for the anchor.
+func (p *parser) withAnchor(anchor string, f func() error) error {
+	if anchor == "" {
+		return f()
+	}
+	tokens := new([]token)
+	p.records = append(p.records, tokens)
+	err := f()
+	p.records = p.records[:len(p.records)-1]
+	p.anchors[anchor[1:]] = *tokens
+	return err
+}
+
+func (p *parser) emit(t token) error {
+	for _, tokens := range p.records {
+		*tokens = append(*tokens, t)
+	}
+	if p.capture != nil {
+		*p.capture = append(*p.capture, t)
+		return nil
+	}
+	if err := p.w.write(t); err != nil {
+		var uerr *unsupportedError
+		if errors.As(err, &uerr) {
+			return &SyntaxError{Line: p.start[0], Column: p.start[1] + 1, Msg: uerr.msg}
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *parser) parseBlockSequence(col int) error {
+	if err := p.emit(token{kind: '['}); err != nil {
+		return err
+	}
+	for {
+		p.col++
+		if err := p.parseNode(col, false, false); err != nil {
+			return err
+		}
+		if p.eof || p.atDocumentMarker() || p.col < col {
+			break
+		}
+		if p.col > col {
+			return p.errorf("bad indentation of a sequence entry")
+		}
+		if !p.atIndicator('-') {
+			break
+		}
+	}
+	return p.emit(token{kind: ']'})
+}
+
+func (p *parser) parseBlockMapping(col int, keyAnchor string) error {
+	if err := p.emit(token{kind: '{'}); err != nil {
+		return err
+	}
+	for {
+		if p.atIndicator('?') {
+			p.col++
+			key, err := p.parseKey(func() error {
+				return p.parseNode(col, false, false)
+			})
+			if err != nil {
+				return err
+			}
+			if err := p.emit(key); err != nil {
+				return err
+			}
+			if !p.eof && !p.atDocumentMarker() && p.col == col && p.atIndicator(':') {
+				p.col++
+				if err := p.parseNode(col, false, true); err != nil {
+					return err
+				}
+			} else if err := p.emit(token{}); err != nil {
+				return err
+			}
+		} else if p.atImplicitKey() {
+			if err := p.withAnchor(keyAnchor, func() error {
+				key, err := p.parseImplicitKey()
+				if err != nil {
+					return err
+				}
+				return p.emit(key)
+			}); err != nil {
+				return err
+			}
+			keyAnchor = ""
+			if err := p.parseNode(col, true, true); err != nil {
+				return err
+			}
+		} else {
+			return p.errorf("could not find expected ':'")
+		}
+		if p.eof || p.atDocumentMarker() || p.col < col {
+			break
+		}
+		if p.col > col {
+			return p.errorf("bad indentation of a mapping entry")
+		}
+	}
+	return p.emit(token{kind: '}'})
+}
+
+// parseKey calls f capturing the tokens, which should be a scalar.
+func (p *parser) parseKey(f func() error) (token, error) {
+	capture, records, tokens := p.capture, p.records, new([]token)
+	p.capture, p.records = tokens, nil
+	err := f()
+	p.capture, p.records = capture, records
+	if err != nil {
+		return token{}, err
+	}
+	if len(*tokens) != 1 || (*tokens)[0].kind != 0 {
+		return token{}, p.errorf("complex mapping key is not supported")
+	}
+	return (*tokens)[0], nil
+}
+
+// atImplicitKey reports whether the current line starts with an implicit key,
+// a single line scalar followed by a mapping value indicator.
+func (p *parser) atImplicitKey() bool {
+	if p.eof {
+		return false
+	}
+	i := p.col
+	if c := p.peek(0); c == '"' || c == '\'' {
+		for i++; ; i++ {
+			if i >= len(p.line) {
+				return false
+			}
+			if p.line[i] == c {
+				if c == '\'' && i+1 < len(p.line) && p.line[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+			if c == '"' && p.line[i] == '\\' {
+				i++
+			}
+		}
+		for i++; i < len(p.line) && isSpace(p.line[i]); i++ {
+		}
+		return i < len(p.line) && p.line[i] == ':' &&
+			(i+1 == len(p.line) || isSpace(p.line[i+1]))
+	}
+	for ; i < len(p.line); i++ {
+		switch p.line[i] {
+		case ':':
+			if i+1 == len(p.line) || isSpace(p.line[i+1]) {
+				return true
+			}
+		case '#':
+			if i > p.col && isSpace(p.line[i-1]) {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+func (p *parser) parseImplicitKey() (token, error) {
+	var t token
+	if c := p.peek(0); c == '"' || c == '\'' {
+		s, err := p.parseQuoted()
+		if err != nil {
+			return t, err
+		}
+		t = token{value: s, style: c}
+		p.skipSpaces()
+	} else {
+		start := p.col
+		for !(p.peek(0) == ':' && (p.peek(1) == 0 || isSpace(p.peek(1)))) {
+			p.col++
+		}
+		t = token{value: strings.TrimRight(p.line[start:p.col], " \t")}
+	}
+	p.col++ // ':'
+	return t, nil
+}
+
+func (p *parser) parseBlockScalar(indent int, tag string) error {
+	style := p.peek(0)
+	var chomp byte
+	var explicit int
+	for p.col++; p.col < len(p.line); p.col++ {
+		if c := p.line[p.col]; c == '+' || c == '-' {
+			chomp = c
+		} else if '1' <= c && c <= '9' {
+			explicit = int(c - '0')
+		} else {
+			break
+		}
+	}
+	if p.skipSpaces(); p.col < len(p.line) && !p.atComment() {
+		return p.errorf("unexpected character %q in block scalar header", p.line[p.col])
+	}
+	contentIndent := -1
+	if explicit > 0 {
+		contentIndent = explicit
+		if indent > 0 {
+			contentIndent += indent
+		}
+	}
+	var lines []string
+	for {
+		if p.nextLine(); p.eof || p.atDocumentMarker() {
+			break
+		}
+		i := 0
+		for i < len(p.line) && p.line[i] == ' ' {
+			i++
+		}
+		if contentIndent < 0 {
+			if i == len(p.line) {
+				lines = append(lines, "")
+				continue
+			}
+			if i <= indent {
+				break
+			}
+			contentIndent = i
+		}
+		if i >= contentIndent {
+			lines = append(lines, p.line[contentIndent:])
+		} else if i == len(p.line) {
+			lines = append(lines, "")
+		} else {
+			break
+		}
+	}
+	p.skipToContent()
+	n := len(lines)
+	for n > 0 && lines[n-1] == "" {
+		n--
+	}
+	var sb strings.Builder
+	if style == '|' {
+		sb.WriteString(strings.Join(lines[:n], "\n"))
+	} else {
+		foldLines(&sb, lines[:n])
+	}
+	if n > 0 && chomp != '-' {
+		sb.WriteByte('\n')
+	}
+	if chomp == '+' {
+		if n == 0 && len(lines) > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(strings.Repeat("\n", len(lines)-n))
+	}
+	return p.emit(token{value: sb.String(), style: style, tag: tag})
+}
+
+// foldLines writes the lines of a folded block scalar.
+func foldLines(sb *strings.Builder, lines []string) {
+	var breaks int
+	var started, prevMore bool
+	for _, l := range lines {
+		if l == "" {
+			breaks++
+			continue
+		}
+		more := isSpace(l[0])
+		if started {
+			if prevMore || more {
+				breaks++
+			}
+			if breaks == 0 {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(strings.Repeat("\n", breaks))
+		sb.WriteString(l)
+		breaks, started, prevMore = 0, true, more
+	}
+}
+
+// parsePlain parses a plain scalar in block context, which may continue in
+// the following lines indented more than indent.
+func (p *parser) parsePlain(indent int) (string, error) {
+	switch c := p.peek(0); c {
+	case '@', '`', '%', ']', '}', ',':
+		return "", p.errorf("found character %q that cannot start a plain scalar", c)
+	}
+	var sb strings.Builder
+	for {
+		start := p.col
+		for ; p.col < len(p.line); p.col++ {
+			if c := p.line[p.col]; c == ':' && (p.col+1 == len(p.line) ||
+				isSpace(p.line[p.col+1])) || p.atComment() {
+				break
+			}
+		}
+		sb.WriteString(strings.TrimRight(p.line[start:p.col], " \t"))
+		if p.col < len(p.line) {
+			if p.atComment() {
+				p.skipToContent()
+				return sb.String(), nil
+			}
+			return "", p.errorf("mapping values are not allowed in this context")
+		}
+		var breaks int
+		for {
+			if p.nextLine(); p.eof {
+				return sb.String(), nil
+			}
+			if p.skipSpaces(); p.col < len(p.line) {
+				break
+			}
+			breaks++
+		}
+		if p.atComment() {
+			p.skipToContent()
+			return sb.String(), nil
+		}
+		if p.col <= indent || p.atDocumentMarker() {
+			return sb.String(), nil
+		}
+		if breaks == 0 {
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteString(strings.Repeat("\n", breaks))
+		}
+	}
+}
+
+// parseQuoted parses a single-quoted or double-quoted scalar.
+func (p *parser) parseQuoted() (string, error) {
+	q := p.peek(0)
+	var bs []byte
+	var keep int // length of bs excluding trailing white spaces
+	for p.col++; ; {
+		if p.col >= len(p.line) {
+			bs = bs[:keep]
+			var breaks int
+			for {
+				if p.nextLine(); p.eof {
+					return "", p.errorf("unexpected end of stream in quoted scalar")
+				}
+				if p.skipSpaces(); p.col < len(p.line) {
+					break
+				}
+				breaks++
+			}
+			if breaks == 0 {
+				bs = append(bs, ' ')
+			} else {
+				bs = append(bs, strings.Repeat("\n", breaks)...)
+			}
+			keep = len(bs)
+			continue
+		}
+		c := p.line[p.col]
+		if c == q {
+			if q == '\'' && p.peek(1) == '\'' {
+				bs = append(bs, '\'')
+				p.col += 2
+				keep = len(bs)
+				continue
+			}
+			p.col++
+			return string(bs), nil
+		}
+		if c == '\\' && q == '"' {
+			if p.col+1 == len(p.line) {
+				if p.nextLine(); p.eof {
+					return "", p.errorf("unexpected end of stream in quoted scalar")
+				}
+				p.skipSpaces()
+				keep = len(bs)
+				continue
+			}
+			var err error
+			if bs, err = p.appendEscape(bs); err != nil {
+				return "", err
+			}
+			keep = len(bs)
+			continue
+		}
+		bs = append(bs, c)
+		p.col++
+		if !isSpace(c) {
+			keep = len(bs)
+		}
+	}
+}
+
+// appendEscape appends the character of the escape sequence.
+func (p *parser) appendEscape(bs []byte) ([]byte, error) {
+	p.col++
+	c := p.line[p.col]
+	p.col++
+	switch c {
+	case '0':
+		return append(bs, 0), nil
+	case 'a':
+		return append(bs, '\a'), nil
+	case 'b':
+		return append(bs, '\b'), nil
+	case 't', '\t':
+		return append(bs, '\t'), nil
+	case 'n':
+		return append(bs, '\n'), nil
+	case 'v':
+		return append(bs, '\v'), nil
+	case 'f':
+		return append(bs, '\f'), nil
+	case 'r':
+		return append(bs, '\r'), nil
+	case 'e':
+		return append(bs, 0x1B), nil
+	case ' ', '"', '/', '\\':
+		return append(bs, c), nil
+	case 'N':
+		return utf8.AppendRune(bs, '\u0085'), nil
+	case '_':
+		return utf8.AppendRune(bs, '\u00A0'), nil
+	case 'L':
+		return utf8.AppendRune(bs, '\u2028'), nil
+	case 'P':
+		return utf8.AppendRune(bs, '\u2029'), nil
+	case 'x', 'u', 'U':
+		n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+		r, ok := p.readHex(n)
+		if !ok {
+			break
+		}
+		if 0xD800 <= r && r < 0xDC00 && p.peek(0) == '\\' && p.peek(1) == 'u' {
+			col := p.col
+			p.col += 2
+			if r2, ok := p.readHex(4); ok && 0xDC00 <= r2 && r2 < 0xE000 {
+				r = (r-0xD800)<<10 + (r2 - 0xDC00) + 0x10000
+			} else {
+				p.col = col
+			}
+		}
+		return utf8.AppendRune(bs, r), nil
+	}
+	p.col -= 2
+	return nil, p.errorf("invalid escape sequence")
+}
+
+func (p *parser) readHex(n int) (rune, bool) {
+	if p.col+n > len(p.line) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range []byte(p.line[p.col : p.col+n]) {
+		switch {
+		case '0' <= c && c <= '9':
+			r = r<<4 | rune(c-'0')
+		case 'a' <= c && c <= 'f':
+			r = r<<4 | rune(c-'a'+10)
+		case 'A' <= c && c <= 'F':
+			r = r<<4 | rune(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	p.col += n
+	return r, true
+}
+
+// skipFlowSpaces skips white spaces, line breaks, and comments in flow context.
+func (p *parser) skipFlowSpaces() error {
+	if p.skipToContent(); p.eof {
+		return p.errorf("unexpected end of stream in flow collection")
+	}
+	return nil
+}
+
+func (p *parser) parseFlowCollection() error {
+	open := p.peek(0)
+	end := byte('}')
+	if open == '[' {
+		end = ']'
+	}
+	p.col++
+	if err := p.emit(token{kind: open}); err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		if err := p.skipFlowSpaces(); err != nil {
+			return err
+		}
+		if p.peek(0) == end {
+			p.col++
+			break
+		}
+		if !first {
+			if p.peek(0) != ',' {
+				return p.errorf("expected ',' or %q in flow collection", end)
+			}
+			p.col++
+			if err := p.skipFlowSpaces(); err != nil {
+				return err
+			}
+			if p.peek(0) == end {
+				p.col++
+				break
+			}
+		}
+		var err error
+		if open == '{' {
+			err = p.parseFlowMappingEntry(end)
+		} else {
+			err = p.parseFlowSequenceEntry()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return p.emit(token{kind: end})
+}
+
+func (p *parser) parseFlowMappingEntry(end byte) error {
+	if p.atIndicator('?') {
+		p.col++
+		if err := p.skipFlowSpaces(); err != nil {
+			return err
+		}
+	}
+	key, err := p.parseKey(p.parseFlowNode)
+	if err != nil {
+		return err
+	}
+	if err := p.emit(key); err != nil {
+		return err
+	}
+	return p.parseFlowMappingValue(end)
+}
+
+func (p *parser) parseFlowMappingValue(end byte) error {
+	if err := p.skipFlowSpaces(); err != nil {
+		return err
+	}
+	if p.peek(0) != ':' {
+		return p.emit(token{})
+	}
+	p.col++
+	if err := p.skipFlowSpaces(); err != nil {
+		return err
+	}
+	if c := p.peek(0); c == ',' || c == end {
+		return p.emit(token{})
+	}
+	return p.parseFlowNode()
+}
+
+func (p *parser) parseFlowSequenceEntry() error {
+	if c := p.peek(0); c == '[' || c == '{' || c == '*' || c == '&' {
+		return p.parseFlowNode()
+	}
+	key, err := p.parseKey(p.parseFlowNode)
+	if err != nil {
+		return err
+	}
+	if err := p.skipFlowSpaces(); err != nil {
+		return err
+	}
+	if p.peek(0) != ':' {
+		return p.emit(key)
+	}
+	for _, t := range []token{{kind: '{'}, key} {
+		if err := p.emit(t); err != nil {
+			return err
+		}
+	}
+	if err := p.parseFlowMappingValue(']'); err != nil {
+		return err
+	}
+	return p.emit(token{kind: '}'})
+}
+
+func (p *parser) parseFlowNode() error {
+	var anchor, tag string
+	for {
+		switch p.peek(0) {
+		case '&':
+			anchor = p.readName()
+		case '!':
+			tag = p.readName()
+		default:
+			p.start = [2]int{p.lineno, p.col}
+			return p.withAnchor(anchor, func() error {
+				return p.parseFlowNodeContent(tag)
+			})
+		}
+		if err := p.skipFlowSpaces(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseFlowNodeContent(tag string) error {
+	switch c := p.peek(0); c {
+	case '[', '{':
+		return p.parseFlowCollection()
+	case '*':
+		return p.parseAlias()
+	case '"', '\'':
+		s, err := p.parseQuoted()
+		if err != nil {
+			return err
+		}
+		return p.emit(token{value: s, style: c, tag: tag})
+	case ',', ']', '}':
+		return p.emit(token{tag: tag})
+	default:
+		start := p.col
+		for ; p.col < len(p.line); p.col++ {
+			if c := p.line[p.col]; isFlowIndicator(c) || p.atComment() ||
+				c == ':' && (p.col+1 == len(p.line) ||
+					isSpace(p.line[p.col+1]) || isFlowIndicator(p.line[p.col+1])) {
+				break
+			}
+		}
+		return p.emit(token{value: strings.TrimRight(p.line[start:p.col], " \t"), tag: tag})
+	}
+}
+
+// unsupportedError represents a YAML value which cannot be converted to JSON.
+type unsupportedError struct {
+	msg string
+}
+
+func (err *unsupportedError) Error() string {
+	return err.msg
+}
+
+// writer writes JSON from the tokens.
+type writer struct {
+	w     io.Writer
+	buf   bytes.Buffer
+	stack []int // number of written tokens in each collection
+	objs  []bool
+	last  byte
+}
+
+func (w *writer) flush() error {
+	if bs := w.buf.Bytes(); len(bs) > 0 {
+		w.last = bs[len(bs)-1]
+	}
+	_, err := w.w.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *writer) lastByte() byte {
+	if bs := w.buf.Bytes(); len(bs) > 0 {
+		return bs[len(bs)-1]
+	}
+	return w.last
+}
+
+func (w *writer) endDocument() error {
+	w.buf.WriteByte('\n')
+	return w.flush()
+}
+
+func (w *writer) write(t token) error {
+	if n := len(w.stack); n > 0 && t.kind != '}' && t.kind != ']' {
+		if w.stack[n-1]++; w.objs[n-1] {
+			if w.stack[n-1]%2 == 1 {
+				if t.kind != 0 {
+					return &unsupportedError{"complex mapping key is not supported"}
+				}
+				if w.stack[n-1] > 1 {
+					w.buf.WriteByte(',')
+				}
+				writeString(&w.buf, t.value)
+				w.buf.WriteByte(':')
+				return nil
+			}
+		} else if w.stack[n-1] > 1 {
+			w.buf.WriteByte(',')
+		}
+	}
+	switch t.kind {
+	case '{', '[':
+		w.stack = append(w.stack, 0)
+		w.objs = append(w.objs, t.kind == '{')
+		w.buf.WriteByte(t.kind)
+		return nil
+	case '}', ']':
+		w.stack, w.objs = w.stack[:len(w.stack)-1], w.objs[:len(w.objs)-1]
+		w.buf.WriteByte(t.kind)
+	default:
+		if err := w.writeScalar(t); err != nil {
+			return err
+		}
+	}
+	if w.buf.Len() > 4*1024 {
+		return w.flush()
+	}
+	return nil
+}
+
+var (
+	intPattern    = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPattern  = regexp.MustCompile(`^[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?$`)
+	infNaNPattern = regexp.MustCompile(`^(?:[-+]?\.(?:inf|Inf|INF)|\.(?:nan|NaN|NAN))$`)
+)
+
+func (w *writer) writeScalar(t token) error {
+	switch t.tag {
+	case "!!str", "!<tag:yaml.org,2002:str>", "!":
+		writeString(&w.buf, t.value)
+		return nil
+	case "!!null", "!!bool", "!!int", "!!float":
+	default:
+		if t.style != 0 {
+			writeString(&w.buf, t.value)
+			return nil
+		}
+	}
+	switch v := t.value; v {
+	case "", "~", "null", "Null", "NULL":
+		w.buf.WriteString("null")
+	case "true", "True", "TRUE":
+		w.buf.WriteString("true")
+	case "false", "False", "FALSE":
+		w.buf.WriteString("false")
+	default:
+		switch {
+		case intPattern.MatchString(v) || floatPattern.MatchString(v):
+			writeNumber(&w.buf, v)
+		case len(v) > 2 && v[0] == '0' && (v[1] == 'o' || v[1] == 'x'):
+			base := 8
+			if v[1] == 'x' {
+				base = 16
+			}
+			if n, ok := new(big.Int).SetString(v[2:], base); ok {
+				w.buf.WriteString(n.String())
+			} else {
+				writeString(&w.buf, v)
+			}
+		case infNaNPattern.MatchString(v):
+			return &unsupportedError{"cannot convert " + v + " to JSON"}
+		default:
+			writeString(&w.buf, v)
+		}
+	}
+	return nil
+}
+
+// writeNumber writes the number in the JSON representation.
+func writeNumber(buf *bytes.Buffer, v string) {
+	switch v[0] {
+	case '-':
+		buf.WriteByte('-')
+		fallthrough
+	case '+':
+		v = v[1:]
+	}
+	i := strings.IndexAny(v, ".eE")
+	if i < 0 {
+		i = len(v)
+	}
+	if integer := strings.TrimLeft(v[:i], "0"); integer == "" {
+		buf.WriteByte('0')
+	} else {
+		buf.WriteString(integer)
+	}
+	v = v[i:]
+	if v != "" && v[0] == '.' {
+		buf.WriteByte('.')
+		if v = v[1:]; v == "" || v[0] == 'e' || v[0] == 'E' {
+			buf.WriteByte('0')
+		}
+	}
+	buf.WriteString(v)
+}
+
+// ref: encodeState#string in encoding/json
+func writeString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b >= ' ' && b != '"' && b != '\\' {
+			continue
+		}
+		buf.WriteString(s[start:i])
+		switch b := s[i]; b {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.Write([]byte{'\\', 'u', '0', '0', hex[b>>4], hex[b&0xF]})
+		}
+		start = i + 1
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
@@ -0,0 +1,339 @@
+package yaml2json_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/json2yaml"
+	"github.com/itchyny/json2yaml/yaml2json"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "empty",
+			src:  "",
+			want: "",
+		},
+		{
+			name: "comments only",
+			src:  "# foo\n\n  # bar\n",
+			want: "",
+		},
+		{
+			name: "null",
+			src:  "null\n--- ~\n--- Null\n---\n",
+			want: "null\nnull\nnull\nnull\n",
+		},
+		{
+			name: "boolean",
+			src:  "[true, True, TRUE, false, False, FALSE, yes, no, on, off]",
+			want: `[true,true,true,false,false,false,"yes","no","on","off"]` + "\n",
+		},
+		{
+			name: "number",
+			src:  "[0, 128, -320, +42, 007, 3.14, -6.63e-34, .5, 1., 1.e3, 0o17, 0x1F, 0xFFFFFFFFFFFFFFFFFF, 1_000, 12:30]",
+			want: `[0,128,-320,42,7,3.14,-6.63e-34,0.5,1.0,1.0e3,15,31,4722366482869645213695,"1_000","12:30"]` + "\n",
+		},
+		{
+			name: "string",
+			src:  `[foo, "null", 'it''s', "\"\\\b\f\r\t\u00e9\x41\U0001F600\ud83d\ude00", hello world, a#b, "a: b", http://example.com]`,
+			want: `["foo","null","it's","\"\\\b\f\r\té` + "A😀😀" + `","hello world","a#b","a: b","http://example.com"]` + "\n",
+		},
+		{
+			name: "multi-line scalars",
+			src: `a: plain
+  text
+
+  more
+b: "double
+  quoted \
+  text"
+c: 'single
+
+  quoted'
+`,
+			want: `{"a":"plain text\nmore","b":"double quoted text","c":"single\nquoted"}` + "\n",
+		},
+		{
+			name: "block scalars",
+			src: `a: |
+  foo
+    bar
+b: |-
+  foo
+c: |+
+  foo
+
+d: >
+  foo
+  bar
+
+  baz
+    qux
+e: |2
+    foo
+f: >-
+
+  foo
+`,
+			want: `{"a":"foo\n  bar\n","b":"foo","c":"foo\n\n","d":"foo bar\nbaz\n  qux\n","e":"  foo\n","f":"\nfoo"}` + "\n",
+		},
+		{
+			name: "block mapping and sequence",
+			src: `foo: # comment
+  bar: 1
+  baz:
+  - 2
+  - - 3
+    - x: 4
+      y:
+        - 5
+  qux:
+    -
+      6
+quux:
+`,
+			want: `{"foo":{"bar":1,"baz":[2,[3,{"x":4,"y":[5]}]],"qux":[6]},"quux":null}` + "\n",
+		},
+		{
+			name: "explicit keys",
+			src: `? |-
+  a
+  b
+:
+  ? c
+  : d
+? e
+"1": f
+2: g
+`,
+			want: `{"a\nb":{"c":"d"},"e":null,"1":"f","2":"g"}` + "\n",
+		},
+		{
+			name: "flow collections",
+			src: `{a: [1, 2, {b: c}], "d": [
+  x, # comment
+  y,
+], e, f: , g: [h: i]}`,
+			want: `{"a":[1,2,{"b":"c"}],"d":["x","y"],"e":null,"f":null,"g":[{"h":"i"}]}` + "\n",
+		},
+		{
+			name: "json",
+			src:  `{"a": [1, 2.50, -3e+10, true, null, "\u3042"], "b": {}, "c": []}`,
+			want: `{"a":[1,2.50,-3e+10,true,null,"あ"],"b":{},"c":[]}` + "\n",
+		},
+		{
+			name: "anchors and aliases",
+			src: `a: &x
+  b: [1, &y 2]
+c: *x
+d: *y
+`,
+			want: `{"a":{"b":[1,2]},"c":{"b":[1,2]},"d":2}` + "\n",
+		},
+		{
+			name: "tags",
+			src:  "[!!str 1, !!int '2', !custom 3, ! true, !!str]",
+			want: `["1",2,3,"true",""]` + "\n",
+		},
+		{
+			name: "multiple documents",
+			src: `%YAML 1.2
+---
+a: 1
+...
+--- b
+---
+- c
+...
+`,
+			want: `{"a":1}` + "\n" + `"b"` + "\n" + `["c"]` + "\n",
+		},
+		{
+			name: "unknown anchor",
+			src:  "a: *x",
+			want: "{\"a\":\n",
+			err:  `line 1, column 4: unknown anchor "x"`,
+		},
+		{
+			name: "mapping value in plain scalar",
+			src:  "a: b: c",
+			want: "{\"a\":\n",
+			err:  "line 1, column 4: mapping values are not allowed in this context",
+		},
+		{
+			name: "bad indentation",
+			src:  "a:\n  b: 1\n c: 2",
+			want: "{\"a\":{\"b\":1}\n",
+			err:  "line 3, column 2: bad indentation of a mapping entry",
+		},
+		{
+			name: "unterminated quoted scalar",
+			src:  `["a", "b`,
+			want: "[\"a\"\n",
+			err:  "unexpected end of stream in quoted scalar",
+		},
+		{
+			name: "unterminated flow collection",
+			src:  "[a, b",
+			want: "[\"a\"\n",
+			err:  "unexpected end of stream in flow collection",
+		},
+		{
+			name: "infinity",
+			src:  "- 1\n- .inf",
+			want: "[1,\n",
+			err:  "line 2, column 3: cannot convert .inf to JSON",
+		},
+		{
+			name: "unexpected content",
+			src:  "- a\nb",
+			want: "[\"a\"]\n",
+			err:  "line 2, column 1: unexpected content after the document",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := yaml2json.Convert(&sb, strings.NewReader(tc.src))
+			if got, want := sb.String(), tc.want; got != want {
+				t.Fatalf("should write\n  %q\nbut got\n  %q", want, got)
+			}
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not raise an error but got: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("should raise an error %q but got no error", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("should raise an error %q but got error %q", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertAliasLimit(t *testing.T) {
+	src := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for c := 'b'; c <= 'i'; c++ {
+		src += fmt.Sprintf("%c: &%[1]c [%s]\n", c, strings.TrimSuffix(strings.Repeat("*"+string(c-1)+", ", 10), ", "))
+	}
+	err := yaml2json.Convert(io.Discard, strings.NewReader(src))
+	if got, want := fmt.Sprint(err), "line 5, column 12: too many tokens expanded by the aliases"; got != want {
+		t.Fatalf("should raise an error %q but got %v", want, err)
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	testCases := []string{
+		`null false true 0 -320 3.14 -6.63e-34 1.50e+3`,
+		`"" "foo" "null" "hello, world" "\"\\\b\f\r\t" " １２３４５ " "true" "y" "0x10" "12:50" "1e3" "-" "- a" "? a" ": a" "a: b" "a #b" "#a" "&a" "*a" "!a" "%a" "@a" "[a]" "{a}" "a,b" "---" "..."`,
+		`"\n" "\n\n" "a\n" "a\n\n" "a\n\n\n" "a \n" "a\t\n" "a\n " "a\n\t" "a\r\n" "a\nb" "a\n\nb" "a\n  b\nc\n" "\na" "\n a" "\n\na" "\na\nb\n\n" "\n\ta\n"`,
+		`[] {} [[]] [{}] {"a":[]} {"a":{}}`,
+		`{"a":[1,[2,{"b":[3,{}]}],{"c":null}],"":{"\n":"\n","y":["a\nb\n",{"a\nb":"c"}]}}`,
+		`[[[{"a":1,"b":[{"c":2}]}]],[{"\na":{"b\n":["c\n"]}}]]`,
+	}
+	for _, src := range testCases {
+		t.Run(src, func(t *testing.T) {
+			testRoundTrip(t, src)
+		})
+	}
+}
+
+func TestConvertRoundTripWithLineWidth(t *testing.T) {
+	testCases := []string{
+		`["\"quoted and more words"] [["\"quoted and more words"]]`,
+		`["\nnew line and more words"] ["\\backslash and more words"]`,
+		`["\u0001control and more words"] ["\u0001\u0002\u0003\u0004\u0005\u0006"]`,
+		`["a\"b\"c\"d\"e\"f\"g\"h"] ["éè あいう éèê"]`,
+		`["ab\u0001\u0002\u0003\u0004\u0005\u0006"] ["abc de\\\"\n\t\u0001\t\"\\ \u0002"]`,
+	}
+	for _, src := range testCases {
+		for width := 1; width <= 12; width++ {
+			t.Run(fmt.Sprintf("%s/%d", src, width), func(t *testing.T) {
+				testRoundTrip(t, src, json2yaml.WithLineWidth(width))
+			})
+		}
+	}
+}
+
+func TestConvertRoundTripWithBlankLines(t *testing.T) {
+	testCases := []string{
+		`{"a":"x\n\n","b":1,"c":"y\n","d":{"e":"z\n\n\n"},"f":[]}`,
+		`{"a":"x\n\n"} {"b":"y\n\n","c":"z\n\n"}`,
+	}
+	for _, src := range testCases {
+		t.Run(src, func(t *testing.T) {
+			testRoundTrip(t, src, json2yaml.WithBlankLines())
+		})
+	}
+}
+
+func testRoundTrip(t *testing.T, src string, opts ...json2yaml.Option) {
+	t.Helper()
+	var yaml, got, want strings.Builder
+	if err := json2yaml.Convert(&yaml, strings.NewReader(src), opts...); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml2json.Convert(&got, strings.NewReader(yaml.String())); err != nil {
+		t.Fatalf("should not raise an error but got: %s\n%s", err, yaml.String())
+	}
+	dec := json.NewDecoder(strings.NewReader(src))
+	for dec.More() {
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, v); err != nil {
+			t.Fatal(err)
+		}
+		want.WriteString(buf.String() + "\n")
+	}
+	if got.String() != want.String() {
+		t.Fatalf("should write\n  %q\nbut got\n  %q\nfrom\n%s", want.String(), got.String(), yaml.String())
+	}
+}
+
+type errWriter struct{}
+
+func (w errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestConvertError(t *testing.T) {
+	err := yaml2json.Convert(errWriter{}, strings.NewReader("foo: bar\n"))
+	if want := "write error"; err == nil || err.Error() != want {
+		t.Fatalf("should raise an error %q but got %v", want, err)
+	}
+}
+
+func ExampleConvert() {
+	input := strings.NewReader(`
+Hello: world!
+list:
+  - 1
+  - foo
+`)
+	var output strings.Builder
+	if err := yaml2json.Convert(&output, input); err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Print(output.String())
+	// Output:
+	// {"Hello":"world!","list":[1,"foo"]}
+}